@@ -0,0 +1,170 @@
+package yolov3
+
+import (
+	"context"
+	"image"
+	"math"
+	"slices"
+	"sort"
+
+	"gocv.io/x/gocv"
+)
+
+// DefaultAugmentMergeThreshold is the default minimum intersection over union of augmented detections to be fused.
+const DefaultAugmentMergeThreshold float32 = 0.55
+
+// AugmentConfig can be used to customise the test-time augmentation of an AugmentedNet.
+type AugmentConfig struct {
+	// Scales at which the frame is detected in addition to its original size. Scales smaller than one
+	// shrink the frame and pad it to its original size, scales larger than one enlarge the frame and
+	// detect it in tiles of the original size. Duplicate scales and scales which are not positive are skipped.
+	Scales []float64
+	// MergeThreshold is the minimum intersection over union of two boxes of the same class for them
+	// to be fused, defaults to DefaultAugmentMergeThreshold
+	MergeThreshold float32
+}
+
+// AugmentedNet is a Net which applies test-time augmentation, trading speed for recall. Objects are
+// detected on the frame and the horizontally flipped frame at the original size and each of the
+// configured scales. The boxes are mapped back onto the frame and fused into a single set of detections.
+type AugmentedNet struct {
+	detector
+	net    Net
+	config AugmentConfig
+}
+
+// NewAugmentedNet creates an augmented net using given net for detecting objects on the augmented frames.
+func NewAugmentedNet(net Net, config AugmentConfig) *AugmentedNet {
+	if config.MergeThreshold <= 0 {
+		config.MergeThreshold = DefaultAugmentMergeThreshold
+	}
+	scales := []float64{}
+	for _, scale := range config.Scales {
+		// The frame is always detected at its original size
+		if scale > 0 && scale != 1 && !slices.Contains(scales, scale) {
+			scales = append(scales, scale)
+		}
+	}
+	config.Scales = scales
+	a := &AugmentedNet{net: net, config: config}
+	a.detector = detector{detect: a.detect}
+	return a
+}
+
+// Close closes the underlying net.
+func (a *AugmentedNet) Close() error {
+	return a.net.Close()
+}
+
+// detect detects the objects in the augmentations of the frame, applying given filter or else the filter of the underlying net.
+func (a *AugmentedNet) detect(ctx context.Context, frame gocv.Mat, filter *Filter) ([]ObjectDetection, error) {
+	detections := []ObjectDetection{}
+	frameSize := image.Pt(frame.Cols(), frame.Rows())
+	for _, scale := range append([]float64{1}, a.config.Scales...) {
+		for _, flip := range []bool{false, true} {
+			augmented, err := a.detectAugmented(ctx, frame, scale, flip, filter)
+			if err != nil {
+				return nil, err
+			}
+			detections = append(detections, augmented...)
+		}
+	}
+	return fuseDetections(detections, a.config.MergeThreshold, frameSize), nil
+}
+
+// detectAugmented detects the objects in the scaled and optionally flipped frame and maps them back onto the frame.
+func (a *AugmentedNet) detectAugmented(ctx context.Context, frame gocv.Mat, scale float64, flip bool, filter *Filter) ([]ObjectDetection, error) {
+	frameSize := image.Pt(frame.Cols(), frame.Rows())
+	augmented := frame
+	if scale != 1 {
+		scaled := gocv.NewMat()
+		// nolint: errcheck
+		defer scaled.Close()
+		size := image.Pt(int(math.Round(float64(frameSize.X)*scale)), int(math.Round(float64(frameSize.Y)*scale)))
+		gocv.Resize(frame, &scaled, size, 0, 0, gocv.InterpolationLinear)
+		augmented = scaled
+	}
+	if flip {
+		flipped := gocv.NewMat()
+		// nolint: errcheck
+		defer flipped.Close()
+		gocv.Flip(augmented, &flipped, 1)
+		augmented = flipped
+	}
+	width := float32(augmented.Cols())
+
+	var detections []ObjectDetection
+	var err error
+	switch {
+	case scale < 1:
+		padded := padFrame(augmented, 0, frameSize.Y-augmented.Rows(), 0, frameSize.X-augmented.Cols())
+		// nolint: errcheck
+		defer padded.Close()
+		detections, err = detectWithFilter(ctx, a.net, padded, filter)
+	case scale > 1:
+		tiled := NewTiledNet(a.net, TileConfig{TileWidth: frameSize.X, TileHeight: frameSize.Y})
+		detections, err = tiled.detect(ctx, augmented, filter)
+	default:
+		detections, err = detectWithFilter(ctx, a.net, augmented, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range detections {
+		box := detections[i].box()
+		if flip {
+			box = Box{MinX: width - box.MaxX, MinY: box.MinY, MaxX: width - box.MinX, MaxY: box.MaxY}
+		}
+		detections[i].setBox(box.Scale(float32(1/scale), float32(1/scale)), frameSize)
+	}
+	return detections, nil
+}
+
+// fuseDetections fuses detections of the same class of which the intersection over union with the most
+// confident detection of a cluster exceeds the threshold. The fused box is the average of the clustered
+// boxes weighted by their confidence, the fused detection keeps the highest confidence.
+func fuseDetections(detections []ObjectDetection, threshold float32, frameSize image.Point) []ObjectDetection {
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].Confidence > detections[j].Confidence
+	})
+	fused := []ObjectDetection{}
+	used := make([]bool, len(detections))
+	for i := range detections {
+		if used[i] {
+			continue
+		}
+		cluster := []ObjectDetection{detections[i]}
+		for j := i + 1; j < len(detections); j++ {
+			if used[j] || detections[j].ClassID != detections[i].ClassID {
+				continue
+			}
+			if detections[i].box().intersectionOverUnion(detections[j].box()) > threshold {
+				cluster = append(cluster, detections[j])
+				used[j] = true
+			}
+		}
+		detection := detections[i]
+		detection.setBox(weightedBox(cluster), frameSize)
+		fused = append(fused, detection)
+	}
+	return fused
+}
+
+// weightedBox calculates the average of the boxes of the detections weighted by their confidence.
+func weightedBox(detections []ObjectDetection) Box {
+	var minX, minY, maxX, maxY, total float64
+	for _, d := range detections {
+		weight := float64(d.Confidence)
+		box := d.box()
+		minX += weight * float64(box.MinX)
+		minY += weight * float64(box.MinY)
+		maxX += weight * float64(box.MaxX)
+		maxY += weight * float64(box.MaxY)
+		total += weight
+	}
+	if total == 0 {
+		return detections[0].box()
+	}
+	return Box{MinX: float32(minX / total), MinY: float32(minY / total), MaxX: float32(maxX / total), MaxY: float32(maxY / total)}
+}
@@ -0,0 +1,107 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *YoloTestSuite) TestAugmentedNet() {
+	laptop := func(confidence float32, box image.Rectangle) ObjectDetection {
+		return ObjectDetection{ClassID: 0, ClassName: "laptop", Confidence: confidence, BoundingBox: box}
+	}
+	fused := func(confidence float32, box Box) ObjectDetection {
+		detection := laptop(confidence, image.Rectangle{})
+		detection.setBox(box, image.Pt(400, 200))
+		return detection
+	}
+	tests := []struct {
+		Name   string
+		Scales []float64
+		// Calls are the detections returned by the fake net in order of the calls
+		Calls  [][]ObjectDetection
+		Result []ObjectDetection
+	}{
+		{
+			Name: "original and flipped",
+			Calls: [][]ObjectDetection{
+				{laptop(0.8, image.Rect(100, 50, 140, 90))},
+				{laptop(0.6, image.Rect(262, 52, 302, 88)), {ClassID: 1, ClassName: "coffee", Confidence: 0.5, BoundingBox: image.Rect(0, 0, 10, 10)}},
+			},
+			Result: []ObjectDetection{
+				// The boxes are averaged weighted by their confidence
+				fused(0.8, Box{MinX: 694.0 / 7, MinY: 356.0 / 7, MaxX: 974.0 / 7, MaxY: 624.0 / 7}),
+				{ClassID: 1, ClassName: "coffee", Confidence: 0.5, BoundingBox: image.Rect(390, 0, 400, 10)},
+			},
+		},
+		{
+			Name:   "downscaled",
+			Scales: []float64{0.5},
+			Calls: [][]ObjectDetection{
+				{},
+				{},
+				{laptop(0.7, image.Rect(50, 25, 70, 45))},
+				{laptop(0.7, image.Rect(130, 25, 150, 45))},
+			},
+			Result: []ObjectDetection{
+				laptop(0.7, image.Rect(100, 50, 140, 90)),
+			},
+		},
+		{
+			Name:   "original size and duplicate scales are detected once",
+			Scales: []float64{1, 0.5, 0.5, 0},
+			Calls: [][]ObjectDetection{
+				{},
+				{},
+				{laptop(0.7, image.Rect(50, 25, 70, 45))},
+				{laptop(0.7, image.Rect(130, 25, 150, 45))},
+			},
+			Result: []ObjectDetection{
+				laptop(0.7, image.Rect(100, 50, 140, 90)),
+			},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			calls := 0
+			net := &fakeNet{detect: func(_ context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+				s.Equal(400, frame.Cols())
+				s.Equal(200, frame.Rows())
+				detections := append([]ObjectDetection{}, test.Calls[calls]...)
+				calls++
+				return detections, nil
+			}}
+			augmented := NewAugmentedNet(net, AugmentConfig{Scales: test.Scales})
+
+			detections, err := augmented.GetDetections(gocv.NewMatWithSize(200, 400, gocv.MatTypeCV8UC3))
+			s.Require().NoError(err)
+			s.Equal(len(test.Calls), calls)
+			s.Equal(withBoxes(image.Pt(400, 200), test.Result), detections)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestAugmentedNetUpscaled() {
+	calls := 0
+	net := &fakeNet{detect: func(_ context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+		// Enlarged frames are detected in tiles of the original frame size
+		s.Equal(400, frame.Cols())
+		s.Equal(200, frame.Rows())
+		calls++
+		return []ObjectDetection{}, nil
+	}}
+	_, err := NewAugmentedNet(net, AugmentConfig{Scales: []float64{2}}).GetDetections(gocv.NewMatWithSize(200, 400, gocv.MatTypeCV8UC3))
+	s.Require().NoError(err)
+	// Original and flipped frame, plus 9 tiles of both the enlarged frame and the enlarged flipped frame
+	s.Equal(2+2*9, calls)
+}
+
+func (s *YoloTestSuite) TestAugmentedNetError() {
+	net := &fakeNet{detect: func(context.Context, gocv.Mat) ([]ObjectDetection, error) {
+		return nil, fmt.Errorf("very broken")
+	}}
+	_, err := NewAugmentedNet(net, AugmentConfig{}).GetDetections(gocv.NewMatWithSize(200, 400, gocv.MatTypeCV8UC3))
+	s.EqualError(err, "very broken")
+}
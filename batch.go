@@ -0,0 +1,113 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// GetDetectionsBatch retrieve predicted detections from given matrices in a single forward pass.
+// The detections are returned per frame, in the order of the frames.
+func (y *yoloNet) GetDetectionsBatch(frames []gocv.Mat) ([][]ObjectDetection, error) {
+	return y.GetDetectionsBatchContext(context.Background(), frames)
+}
+
+// GetDetectionsBatchContext retrieve predicted detections from given matrices in a single forward pass,
+// unless the context is done.
+func (y *yoloNet) GetDetectionsBatchContext(ctx context.Context, frames []gocv.Mat) ([][]ObjectDetection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return [][]ObjectDetection{}, nil
+	}
+
+	outputs, err := y.forwardBatch(ctx, frames)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(outputs); i++ {
+		// nolint: errcheck
+		defer outputs[i].Close()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := [][]ObjectDetection{}
+	for i, frame := range frames {
+		detections, err := y.processBatchOutputs(frame, outputs, i, len(frames))
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+		result = append(result, detections)
+	}
+	return result, nil
+}
+
+// forwardBatch runs the frames through the net, the caller is responsible for closing the outputs.
+func (y *yoloNet) forwardBatch(ctx context.Context, frames []gocv.Mat) ([]gocv.Mat, error) {
+	blob := blobFromFrames(frames, image.Pt(y.DefaultInputWidth, y.DefaultInputHeight), y.resizeMode)
+	// nolint: errcheck
+	defer blob.Close()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	y.net.SetInput(blob, y.inputName)
+
+	return y.net.ForwardLayers(y.outputLayers), nil
+}
+
+// processBatchOutputs processes the part of the batch outputs which belongs to the frame at given index.
+func (y *yoloNet) processBatchOutputs(frame gocv.Mat, outputs []gocv.Mat, index, batchSize int) ([]ObjectDetection, error) {
+	frameOutputs := []gocv.Mat{}
+	defer func() {
+		for i := 0; i < len(frameOutputs); i++ {
+			// nolint: errcheck
+			frameOutputs[i].Close()
+		}
+	}()
+	for i := 0; i < len(outputs); i++ {
+		output, err := splitBatchOutput(outputs[i], index, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		frameOutputs = append(frameOutputs, output)
+	}
+	return y.processOutputs(frame, frameOutputs, y.filter)
+}
+
+// splitBatchOutput copies the part of an output layer which belongs to the image at given index of the batch.
+// Outputs either have a leading batch dimension, or, as the Darknet yolo layers, have the detections
+// of all images concatenated in their rows.
+func splitBatchOutput(output gocv.Mat, index, batchSize int) (gocv.Mat, error) {
+	size := output.Size()
+	var shape []int
+	switch {
+	case len(size) > 2 && size[0] == batchSize:
+		shape = size[1:]
+	case len(size) == 2 && size[0]%batchSize == 0:
+		shape = []int{size[0] / batchSize, size[1]}
+	default:
+		return gocv.Mat{}, fmt.Errorf("unable to split output layer of shape %v into a batch of %d", size, batchSize)
+	}
+
+	data, err := output.DataPtrFloat32()
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+	length := len(data) / batchSize
+	frameOutput := gocv.NewMatWithSizes(shape, gocv.MatTypeCV32F)
+	frameData, err := frameOutput.DataPtrFloat32()
+	if err != nil {
+		// nolint: errcheck
+		frameOutput.Close()
+		return gocv.Mat{}, err
+	}
+	copy(frameData, data[index*length:(index+1)*length])
+	return frameOutput, nil
+}
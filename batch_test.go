@@ -0,0 +1,138 @@
+package yolov3
+
+import (
+	"context"
+	"image"
+
+	"github.com/golang/mock/gomock"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+)
+
+// darknetBatchOutput creates a Darknet output layer for a batch of two images, of which the first
+// image contains a laptop and the second image a coffee.
+func darknetBatchOutput() gocv.Mat {
+	output := gocv.NewMatWithSize(2, 7, gocv.MatTypeCV32F)
+	for row, classID := range []int{0, 1} {
+		output.SetFloatAt(row, 0, 0.5)
+		output.SetFloatAt(row, 1, 0.5)
+		output.SetFloatAt(row, 2, 0.5)
+		output.SetFloatAt(row, 3, 0.5)
+		output.SetFloatAt(row, 5+classID, 9)
+	}
+	return output
+}
+
+// yoloV8BatchOutput creates a YOLOv8 output layer for a batch of two images with a single candidate
+// each, of which the first image contains a coffee and the second image a laptop.
+func yoloV8BatchOutput() gocv.Mat {
+	output := gocv.NewMatWithSizes([]int{2, 6, 1}, gocv.MatTypeCV32F)
+	for image, classID := range []int{1, 0} {
+		output.SetFloatAt3(image, 0, 0, 320)
+		output.SetFloatAt3(image, 1, 0, 320)
+		output.SetFloatAt3(image, 2, 0, 320)
+		output.SetFloatAt3(image, 3, 0, 320)
+		output.SetFloatAt3(image, 4+classID, 0, 9)
+	}
+	return output
+}
+
+func (s *YoloTestSuite) TestGetDetectionsBatch() {
+	tests := []struct {
+		Name        string
+		ModelKind   ModelKind
+		Output      func() gocv.Mat
+		Result      [][]ObjectDetection
+		ExpectError bool
+	}{
+		{
+			Name:      "Darknet detections concatenated in the rows",
+			ModelKind: ModelYoloV3,
+			Output:    darknetBatchOutput,
+			Result: [][]ObjectDetection{
+				{{ClassID: 0, ClassName: "laptop", Confidence: 9, BoundingBox: image.Rect(25, 25, 75, 75)}},
+				{{ClassID: 1, ClassName: "coffee", Confidence: 9, BoundingBox: image.Rect(50, 25, 150, 75)}},
+			},
+		},
+		{
+			Name:      "YOLOv8 leading batch dimension",
+			ModelKind: ModelYoloV8,
+			Output:    yoloV8BatchOutput,
+			Result: [][]ObjectDetection{
+				{{ClassID: 1, ClassName: "coffee", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(25, 25, 75, 75)}},
+				{{ClassID: 0, ClassName: "laptop", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(50, 25, 150, 75)}},
+			},
+		},
+		{
+			Name:      "Output can not be split",
+			ModelKind: ModelYoloV3,
+			Output: func() gocv.Mat {
+				return gocv.NewMatWithSize(3, 7, gocv.MatTypeCV32F)
+			},
+			ExpectError: true,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			controller := gomock.NewController(s.T())
+			neuralNetMock := mocks.NewMockNeuralNet(controller)
+			neuralNetMock.EXPECT().SetInput(gomock.Any(), "").Times(1)
+			neuralNetMock.EXPECT().ForwardLayers([]string{"output"}).Return([]gocv.Mat{test.Output()}).Times(1)
+
+			config := DefaultConfigForModel(test.ModelKind)
+			s.Require().NoError(config.validate())
+			y := &yoloNet{
+				net:                 neuralNetMock,
+				labels:              labelsFromNames([]string{"laptop", "coffee"}),
+				outputLayers:        []string{"output"},
+				decoder:             config.OutputDecoder,
+				DefaultInputWidth:   config.InputWidth,
+				DefaultInputHeight:  config.InputHeight,
+				confidenceThreshold: config.ConfidenceThreshold,
+				DefaultNMSThreshold: config.NMSThreshold,
+			}
+
+			// The frames have different sizes, the boxes are scaled to each frame
+			frames := []gocv.Mat{
+				gocv.NewMatWithSize(100, 100, gocv.MatTypeCV8UC3),
+				gocv.NewMatWithSize(100, 200, gocv.MatTypeCV8UC3),
+			}
+			detections, err := y.GetDetectionsBatch(frames)
+			if test.ExpectError {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Require().Len(detections, len(test.Result))
+			for i, frame := range frames {
+				s.Equal(withBoxes(image.Pt(frame.Cols(), frame.Rows()), test.Result[i]), detections[i])
+			}
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestGetDetectionsBatchWithoutFrames() {
+	y := &yoloNet{}
+	detections, err := y.GetDetectionsBatch(nil)
+	s.Require().NoError(err)
+	s.Empty(detections)
+}
+
+func (s *YoloTestSuite) TestGetDetectionsBatchCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	controller := gomock.NewController(s.T())
+	neuralNetMock := mocks.NewMockNeuralNet(controller)
+	neuralNetMock.EXPECT().SetInput(gomock.Any(), "").Times(1)
+	neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).DoAndReturn(func([]string) []gocv.Mat {
+		cancel()
+		return []gocv.Mat{darknetBatchOutput()}
+	}).Times(1)
+
+	y := &yoloNet{net: neuralNetMock, labels: labelsFromNames([]string{"laptop", "coffee"})}
+	_, err := y.GetDetectionsBatchContext(ctx, []gocv.Mat{
+		gocv.NewMatWithSize(100, 100, gocv.MatTypeCV8UC3),
+		gocv.NewMatWithSize(100, 200, gocv.MatTypeCV8UC3),
+	})
+	s.ErrorIs(err, context.Canceled)
+}
@@ -0,0 +1,128 @@
+package yolov3
+
+import (
+	"image"
+	"math"
+
+	"github.com/wimspaargaren/yolov3/internal/nms"
+)
+
+// Box is a bounding box with sub-pixel precision, formatted as the left, top, right and bottom coordinates.
+type Box struct {
+	MinX, MinY, MaxX, MaxY float32
+}
+
+// BoxFromXYXY creates a box from its left, top, right and bottom coordinates.
+func BoxFromXYXY(xyxy [4]float32) Box {
+	return Box{MinX: xyxy[0], MinY: xyxy[1], MaxX: xyxy[2], MaxY: xyxy[3]}
+}
+
+// BoxFromXYWH creates a box from its left and top coordinates, width and height.
+func BoxFromXYWH(xywh [4]float32) Box {
+	return Box{MinX: xywh[0], MinY: xywh[1], MaxX: xywh[0] + xywh[2], MaxY: xywh[1] + xywh[3]}
+}
+
+// BoxFromCXCYWH creates a box from its center coordinates, width and height.
+func BoxFromCXCYWH(cxcywh [4]float32) Box {
+	halfWidth, halfHeight := cxcywh[2]/2, cxcywh[3]/2
+	return Box{MinX: cxcywh[0] - halfWidth, MinY: cxcywh[1] - halfHeight, MaxX: cxcywh[0] + halfWidth, MaxY: cxcywh[1] + halfHeight}
+}
+
+// BoxFromRect creates a box from a rectangle.
+func BoxFromRect(r image.Rectangle) Box {
+	return Box{MinX: float32(r.Min.X), MinY: float32(r.Min.Y), MaxX: float32(r.Max.X), MaxY: float32(r.Max.Y)}
+}
+
+// XYXY retrieves the left, top, right and bottom coordinates of the box.
+func (b Box) XYXY() [4]float32 {
+	return [4]float32{b.MinX, b.MinY, b.MaxX, b.MaxY}
+}
+
+// XYWH retrieves the left and top coordinates, width and height of the box.
+func (b Box) XYWH() [4]float32 {
+	return [4]float32{b.MinX, b.MinY, b.MaxX - b.MinX, b.MaxY - b.MinY}
+}
+
+// CXCYWH retrieves the center coordinates, width and height of the box.
+func (b Box) CXCYWH() [4]float32 {
+	return [4]float32{(b.MinX + b.MaxX) / 2, (b.MinY + b.MaxY) / 2, b.MaxX - b.MinX, b.MaxY - b.MinY}
+}
+
+// Rect rounds the coordinates of the box to whole pixels.
+func (b Box) Rect() image.Rectangle {
+	round := func(v float32) int {
+		// Round half up, such that shifting a box by whole pixels shifts its rectangle equally
+		return int(math.Floor(float64(v) + 0.5))
+	}
+	return image.Rect(round(b.MinX), round(b.MinY), round(b.MaxX), round(b.MaxY))
+}
+
+// Add translates the box by given offset.
+func (b Box) Add(p image.Point) Box {
+	x, y := float32(p.X), float32(p.Y)
+	return Box{MinX: b.MinX + x, MinY: b.MinY + y, MaxX: b.MaxX + x, MaxY: b.MaxY + y}
+}
+
+// Scale multiplies the horizontal and vertical coordinates of the box by given factors.
+func (b Box) Scale(x, y float32) Box {
+	return Box{MinX: b.MinX * x, MinY: b.MinY * y, MaxX: b.MaxX * x, MaxY: b.MaxY * y}
+}
+
+// Union retrieves the smallest box containing both boxes.
+func (b Box) Union(o Box) Box {
+	return Box{MinX: min(b.MinX, o.MinX), MinY: min(b.MinY, o.MinY), MaxX: max(b.MaxX, o.MaxX), MaxY: max(b.MaxY, o.MaxY)}
+}
+
+// Clip clips the box to a frame of given size.
+func (b Box) Clip(frameSize image.Point) Box {
+	width, height := float32(frameSize.X), float32(frameSize.Y)
+	clip := func(v, limit float32) float32 {
+		return min(max(v, 0), limit)
+	}
+	return Box{MinX: clip(b.MinX, width), MinY: clip(b.MinY, height), MaxX: clip(b.MaxX, width), MaxY: clip(b.MaxY, height)}
+}
+
+// Normalise maps the box in pixels onto coordinates relative to the size of the frame.
+func (b Box) Normalise(frameSize image.Point) Box {
+	if frameSize.X == 0 || frameSize.Y == 0 {
+		return Box{}
+	}
+	width, height := float32(frameSize.X), float32(frameSize.Y)
+	return Box{MinX: b.MinX / width, MinY: b.MinY / height, MaxX: b.MaxX / width, MaxY: b.MaxY / height}
+}
+
+// Denormalise maps the box relative to the size of the frame onto pixels.
+func (b Box) Denormalise(frameSize image.Point) Box {
+	return b.Scale(float32(frameSize.X), float32(frameSize.Y))
+}
+
+// box retrieves the sub-pixel box of the detection, falling back to the bounding box for
+// detections of nets which only report whole pixels.
+func (d ObjectDetection) box() Box {
+	if d.Box == (Box{}) {
+		return BoxFromRect(d.BoundingBox)
+	}
+	return d.Box
+}
+
+// setBox sets the boxes of the detection to given box in pixels of a frame of given size.
+func (d *ObjectDetection) setBox(box Box, frameSize image.Point) {
+	d.Box = box
+	d.NormalisedBox = box.Normalise(frameSize)
+	d.BoundingBox = box.Rect()
+}
+
+// area calculates the area of the box, which is zero for empty boxes.
+func (b Box) area() float32 {
+	return nms.Area(nms.Box(b))
+}
+
+// intersectionOverUnion calculates the area of the intersection of two boxes relative to the area of their union.
+func (b Box) intersectionOverUnion(o Box) float32 {
+	return nms.IoU(nms.Box(b), nms.Box(o))
+}
+
+// intersectionOverSmaller calculates the area of the intersection of two boxes relative to the area of the smaller box.
+func (b Box) intersectionOverSmaller(o Box) float32 {
+	return nms.IoS(nms.Box(b), nms.Box(o))
+}
@@ -0,0 +1,115 @@
+package yolov3
+
+import (
+	"image"
+)
+
+func (s *YoloTestSuite) TestBoxFormats() {
+	box := Box{MinX: 10, MinY: 20, MaxX: 50, MaxY: 80}
+	tests := []struct {
+		Name   string
+		Coords [4]float32
+		From   func([4]float32) Box
+		To     func(Box) [4]float32
+	}{
+		{
+			Name:   "xyxy",
+			Coords: [4]float32{10, 20, 50, 80},
+			From:   BoxFromXYXY,
+			To:     Box.XYXY,
+		},
+		{
+			Name:   "xywh",
+			Coords: [4]float32{10, 20, 40, 60},
+			From:   BoxFromXYWH,
+			To:     Box.XYWH,
+		},
+		{
+			Name:   "cxcywh",
+			Coords: [4]float32{30, 50, 40, 60},
+			From:   BoxFromCXCYWH,
+			To:     Box.CXCYWH,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(box, test.From(test.Coords))
+			s.Equal(test.Coords, test.To(box))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestBoxRect() {
+	tests := []struct {
+		Name   string
+		Box    Box
+		Result image.Rectangle
+	}{
+		{
+			Name:   "whole pixels",
+			Box:    Box{MinX: -1, MinY: 1, MaxX: 1, MaxY: 3},
+			Result: image.Rect(-1, 1, 1, 3),
+		},
+		{
+			Name:   "sub-pixel",
+			Box:    Box{MinX: 0.4, MinY: 0.5, MaxX: 10.6, MaxY: 10.49},
+			Result: image.Rect(0, 1, 11, 10),
+		},
+		{
+			Name:   "negative halves round up",
+			Box:    Box{MinX: -2.5, MinY: -0.5, MaxX: 0.5, MaxY: 1.5},
+			Result: image.Rect(-2, 0, 1, 2),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.Result, test.Box.Rect())
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestBoxClip() {
+	tests := []struct {
+		Name   string
+		Box    Box
+		Result Box
+	}{
+		{
+			Name:   "inside the frame",
+			Box:    Box{MinX: 10, MinY: 10, MaxX: 20, MaxY: 20},
+			Result: Box{MinX: 10, MinY: 10, MaxX: 20, MaxY: 20},
+		},
+		{
+			Name:   "beyond the edges",
+			Box:    Box{MinX: -5.5, MinY: -1, MaxX: 120, MaxY: 60.5},
+			Result: Box{MinX: 0, MinY: 0, MaxX: 100, MaxY: 50},
+		},
+		{
+			Name:   "outside the frame",
+			Box:    Box{MinX: 110, MinY: 10, MaxX: 120, MaxY: 20},
+			Result: Box{MinX: 100, MinY: 10, MaxX: 100, MaxY: 20},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.Result, test.Box.Clip(image.Pt(100, 50)))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestBoxNormalise() {
+	box := Box{MinX: 25, MinY: 10, MaxX: 75, MaxY: 40}
+	normalised := box.Normalise(image.Pt(100, 50))
+	s.Equal(Box{MinX: 0.25, MinY: 0.2, MaxX: 0.75, MaxY: 0.8}, normalised)
+	s.Equal(box, normalised.Denormalise(image.Pt(100, 50)))
+	s.Equal(Box{}, box.Normalise(image.Pt(0, 0)))
+}
+
+func (s *YoloTestSuite) TestObjectDetectionBox() {
+	// Nets which only report whole pixels fall back to the bounding box
+	s.Equal(Box{MinX: 10, MinY: 20, MaxX: 30, MaxY: 40}, ObjectDetection{BoundingBox: image.Rect(10, 20, 30, 40)}.box())
+	s.Equal(Box{MinX: 10.5, MinY: 20, MaxX: 30, MaxY: 40}, ObjectDetection{
+		BoundingBox: image.Rect(11, 20, 30, 40),
+		Box:         Box{MinX: 10.5, MinY: 20, MaxX: 30, MaxY: 40},
+	}.box())
+}
@@ -0,0 +1,30 @@
+package yolov3
+
+import (
+	"context"
+
+	"github.com/wimspaargaren/yolov3/models"
+)
+
+// NewNetFromModel creates new yolo net for a model of the default catalog, such as "yolov3" or "yolov4-tiny".
+// The model is downloaded into the default cache dir if not present yet.
+func NewNetFromModel(ctx context.Context, name string) (Net, error) {
+	manager, err := models.NewManager("")
+	if err != nil {
+		return nil, err
+	}
+	// Models without a known topology are treated as custom models
+	kind, _ := ParseModelKind(name)
+	config := DefaultConfigForModel(kind)
+	return NewNetFromModelWithConfig(ctx, manager, name, config)
+}
+
+// NewNetFromModelWithConfig creates new yolo net with given config for a model of the catalog of given manager.
+// The model is downloaded into the cache dir of the manager if not present yet.
+func NewNetFromModelWithConfig(ctx context.Context, manager *models.Manager, name string, config Config) (Net, error) {
+	paths, err := manager.Ensure(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewNetWithConfig(paths.Weights, paths.Config, paths.Names, config)
+}
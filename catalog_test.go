@@ -0,0 +1,50 @@
+package yolov3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/wimspaargaren/yolov3/internal/ml"
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+	"github.com/wimspaargaren/yolov3/models"
+)
+
+func (s *YoloTestSuite) TestNewNetFromModelWithConfig() {
+	server := httptest.NewServer(http.FileServer(http.Dir("data/yolov3")))
+	defer server.Close()
+
+	manager := &models.Manager{
+		CacheDir: s.T().TempDir(),
+		Catalog: []models.Model{
+			{
+				Name:    "yolov3",
+				Weights: models.File{Name: "yolov3.weights", URL: server.URL + "/yolov3.weights", TrustOnFirstUse: true},
+				Config:  models.File{Name: "yolov3.cfg", URL: server.URL + "/yolov3.cfg", TrustOnFirstUse: true},
+				Names:   models.File{Name: "coco.names", URL: server.URL + "/coco.names", TrustOnFirstUse: true},
+			},
+		},
+		Client: server.Client(),
+	}
+
+	config := DefaultConfig()
+	config.NewNet = func(weightsPath, configPath string) ml.NeuralNet {
+		s.Equal(filepath.Join(manager.CacheDir, "yolov3", "yolov3.weights"), weightsPath)
+		s.Equal(filepath.Join(manager.CacheDir, "yolov3", "yolov3.cfg"), configPath)
+		controller := gomock.NewController(s.T())
+		neuralNetMock := mocks.NewMockNeuralNet(controller)
+		neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+		neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+		return neuralNetMock
+	}
+
+	net, err := NewNetFromModelWithConfig(context.Background(), manager, "yolov3", config)
+	s.Require().NoError(err)
+	s.Equal(80, len(net.(*yoloNet).labels))
+
+	_, err = NewNetFromModelWithConfig(context.Background(), manager, "notexistent", config)
+	s.EqualError(err, "unknown model: notexistent")
+}
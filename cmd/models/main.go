@@ -0,0 +1,49 @@
+// Package main provides a command line tool to list and download the pre-trained yolo models.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/wimspaargaren/yolov3/models"
+)
+
+func main() {
+	cacheDir := flag.String("cache-dir", "", "specify the directory the models are downloaded to, defaults to $"+models.CacheDirEnv+" or the user cache dir")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] list | download <model>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	manager, err := models.NewManager(*cacheDir)
+	if err != nil {
+		log.WithError(err).Fatal("unable to create model manager")
+	}
+
+	switch flag.Arg(0) {
+	case "list":
+		for _, model := range manager.Catalog {
+			fmt.Println(model.Name)
+		}
+	case "download":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		for _, name := range flag.Args()[1:] {
+			paths, err := manager.Ensure(context.Background(), name)
+			if err != nil {
+				log.WithError(err).Fatalf("unable to download model %s", name)
+			}
+			log.WithField("weights", paths.Weights).WithField("config", paths.Config).WithField("names", paths.Names).Infof("downloaded model %s", name)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path"
 
@@ -44,29 +45,39 @@ func main() {
 		}
 	}()
 
-	frame := gocv.NewMat()
-	defer func() {
-		err := frame.Close()
-		if err != nil {
-			log.WithError(err).Errorf("unable to close image")
+	// Capture frames in the background, such that the latency does not build up whenever
+	// detection is slower than the camera
+	frames := make(chan gocv.Mat)
+	go func() {
+		defer close(frames)
+		for {
+			frame := gocv.NewMat()
+			if ok := videoCapture.Read(&frame); !ok {
+				log.Error("unable to read videostram")
+			}
+			if frame.Empty() {
+				// nolint: errcheck
+				frame.Close()
+				continue
+			}
+			frames <- frame
 		}
 	}()
 
-	for {
-		if ok := videoCapture.Read(&frame); !ok {
-			log.Error("unable to read videostram")
-		}
-		if frame.Empty() {
-			continue
-		}
-		detections, err := yolonet.GetDetections(frame)
-		if err != nil {
-			log.WithError(err).Fatal("unable to retrieve predictions")
+	results := yolov3.Stream(context.Background(), yolonet, frames, yolov3.StreamConfig{Mode: yolov3.StreamLatestFrame})
+	for result := range results {
+		if result.Err != nil {
+			log.WithError(result.Err).Fatal("unable to retrieve predictions")
 		}
 
-		yolov3.DrawDetections(&frame, detections)
+		yolov3.DrawDetections(&result.Frame, result.Detections)
 
-		window.IMShow(frame)
+		window.IMShow(result.Frame)
 		window.WaitKey(1)
+
+		err := result.Frame.Close()
+		if err != nil {
+			log.WithError(err).Errorf("unable to close image")
+		}
 	}
 }
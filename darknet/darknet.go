@@ -0,0 +1,206 @@
+// Package darknet provides a parser for Darknet .cfg files, used to derive the metadata of a yolo network.
+package darknet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config contains the metadata of a network described by a Darknet .cfg file.
+type Config struct {
+	// Width, Height & Channels of the input of the network, as configured in the [net] section
+	Width    int
+	Height   int
+	Channels int
+	// YoloLayers contains the [yolo] sections of the network in order of appearance
+	YoloLayers []YoloLayer
+}
+
+// Anchor is the width and height of an anchor box in pixels of the input.
+type Anchor struct {
+	Width  float32
+	Height float32
+}
+
+// YoloLayer contains the settings of a [yolo] section.
+type YoloLayer struct {
+	// Index of the layer in the network, not counting the [net] section
+	Index   int
+	Classes int
+	Anchors []Anchor
+	Mask    []int
+	ScaleXY float32
+}
+
+// OutputLayerName returns the name of the layer as assigned by the OpenCV Darknet importer.
+func (l YoloLayer) OutputLayerName() string {
+	return fmt.Sprintf("yolo_%d", l.Index)
+}
+
+// OutputLayerNames returns the names of the yolo layers, which are the output layers of the network.
+func (c *Config) OutputLayerNames() []string {
+	names := []string{}
+	for _, layer := range c.YoloLayers {
+		names = append(names, layer.OutputLayerName())
+	}
+	return names
+}
+
+// Classes returns the amount of classes detected by the network, or zero if it contains no yolo layers.
+func (c *Config) Classes() int {
+	if len(c.YoloLayers) == 0 {
+		return 0
+	}
+	return c.YoloLayers[0].Classes
+}
+
+// ParseFile parses the Darknet .cfg file at given path.
+func ParseFile(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(content))
+}
+
+// Parse parses a Darknet .cfg file from given reader.
+func Parse(r io.Reader) (*Config, error) {
+	config := &Config{}
+	section := ""
+	index := -1
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := stripComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			switch section {
+			case "net", "network":
+			case "yolo":
+				index++
+				config.YoloLayers = append(config.YoloLayers, YoloLayer{Index: index, ScaleXY: 1})
+			default:
+				index++
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key=value, got %q", lineNumber, line)
+		}
+		if section == "" {
+			return nil, fmt.Errorf("line %d: option %q outside of a section", lineNumber, strings.TrimSpace(key))
+		}
+		err := config.setOption(section, strings.TrimSpace(key), strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, layer := range config.YoloLayers {
+		if layer.Classes != config.Classes() {
+			return nil, fmt.Errorf("yolo layers have a different amount of classes: %d and %d", config.Classes(), layer.Classes)
+		}
+	}
+	return config, nil
+}
+
+// setOption sets the option of given section, options which are not part of the metadata are ignored.
+func (c *Config) setOption(section, key, value string) error {
+	var err error
+	switch section {
+	case "net", "network":
+		switch key {
+		case "width":
+			c.Width, err = parseInt(key, value)
+		case "height":
+			c.Height, err = parseInt(key, value)
+		case "channels":
+			c.Channels, err = parseInt(key, value)
+		}
+	case "yolo":
+		layer := &c.YoloLayers[len(c.YoloLayers)-1]
+		switch key {
+		case "classes":
+			layer.Classes, err = parseInt(key, value)
+		case "mask":
+			layer.Mask, err = parseInts(key, value)
+		case "anchors":
+			layer.Anchors, err = parseAnchors(value)
+		case "scale_x_y":
+			layer.ScaleXY, err = parseFloat(key, value)
+		}
+	}
+	return err
+}
+
+// stripComment removes comments and surrounding whitespace from a line.
+func stripComment(line string) string {
+	if i := strings.IndexAny(line, "#;"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+func parseInt(key, value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %q", key, value)
+	}
+	return i, nil
+}
+
+func parseFloat(key, value string) (float32, error) {
+	f, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %q", key, value)
+	}
+	return float32(f), nil
+}
+
+func parseInts(key, value string) ([]int, error) {
+	ints := []int{}
+	for _, field := range strings.Split(value, ",") {
+		i, err := parseInt(key, strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
+
+func parseAnchors(value string) ([]Anchor, error) {
+	values := []float32{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		f, err := parseFloat("anchors", field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, f)
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("anchors should consist of width and height pairs, got %d values", len(values))
+	}
+	anchors := []Anchor{}
+	for i := 0; i < len(values); i += 2 {
+		anchors = append(anchors, Anchor{Width: values[i], Height: values[i+1]})
+	}
+	return anchors, nil
+}
@@ -0,0 +1,151 @@
+package darknet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DarknetTestSuite struct {
+	suite.Suite
+}
+
+func TestDarknetTestSuite(t *testing.T) {
+	suite.Run(t, new(DarknetTestSuite))
+}
+
+const tinyConfig = `[net]
+# Testing
+batch=1
+width=416
+height=320
+channels=3
+
+[convolutional]
+filters=16
+size=3
+
+[maxpool]
+size=2
+
+[convolutional]
+filters=255
+activation=linear
+
+[yolo]
+mask = 3,4,5
+anchors = 10,14,  23,27,  37,58,  81,82,  135,169,  344,319
+classes=80
+num=6
+
+[route]
+layers = -4
+
+[upsample]
+stride=2
+
+[convolutional]
+filters=255 ; inline comment
+
+[yolo]
+mask = 0,1,2
+anchors = 10,14,  23,27,  37,58,  81,82,  135,169,  344,319
+classes=80
+num=6
+scale_x_y = 1.05
+`
+
+func (s *DarknetTestSuite) TestParse() {
+	config, err := Parse(strings.NewReader(tinyConfig))
+	s.Require().NoError(err)
+
+	anchors := []Anchor{{10, 14}, {23, 27}, {37, 58}, {81, 82}, {135, 169}, {344, 319}}
+	s.Equal(&Config{
+		Width:    416,
+		Height:   320,
+		Channels: 3,
+		YoloLayers: []YoloLayer{
+			{Index: 3, Classes: 80, Anchors: anchors, Mask: []int{3, 4, 5}, ScaleXY: 1},
+			{Index: 7, Classes: 80, Anchors: anchors, Mask: []int{0, 1, 2}, ScaleXY: 1.05},
+		},
+	}, config)
+	s.Equal([]string{"yolo_3", "yolo_7"}, config.OutputLayerNames())
+	s.Equal(80, config.Classes())
+}
+
+func (s *DarknetTestSuite) TestParseFile() {
+	config, err := ParseFile("../data/yolov3/yolov3.cfg")
+	s.Require().NoError(err)
+	s.Equal(416, config.Width)
+	s.Equal(416, config.Height)
+	s.Equal(3, config.Channels)
+	s.Equal([]string{"yolo_82", "yolo_94", "yolo_106"}, config.OutputLayerNames())
+	s.Equal(80, config.Classes())
+
+	_, err = ParseFile("notexistent")
+	s.Error(err)
+}
+
+func (s *DarknetTestSuite) TestParseErrors() {
+	tests := []struct {
+		Name  string
+		Input string
+		Error string
+	}{
+		{
+			Name:  "Missing value",
+			Input: "[net]\nwidth\n",
+			Error: `line 2: expected key=value, got "width"`,
+		},
+		{
+			Name:  "Option outside of section",
+			Input: "width=416\n",
+			Error: `line 1: option "width" outside of a section`,
+		},
+		{
+			Name:  "Invalid integer",
+			Input: "[net]\nwidth=wide\n",
+			Error: `line 2: invalid value for width: "wide"`,
+		},
+		{
+			Name:  "Invalid mask",
+			Input: "[yolo]\nmask=0,one\n",
+			Error: `line 2: invalid value for mask: "one"`,
+		},
+		{
+			Name:  "Invalid scale",
+			Input: "[yolo]\nscale_x_y=big\n",
+			Error: `line 2: invalid value for scale_x_y: "big"`,
+		},
+		{
+			Name:  "Odd amount of anchors",
+			Input: "[yolo]\nanchors=10,14,23\n",
+			Error: "line 2: anchors should consist of width and height pairs, got 3 values",
+		},
+		{
+			Name:  "Invalid anchor",
+			Input: "[yolo]\nanchors=10,x\n",
+			Error: `line 2: invalid value for anchors: "x"`,
+		},
+		{
+			Name:  "Different amount of classes",
+			Input: "[yolo]\nclasses=80\n[yolo]\nclasses=2\n",
+			Error: "yolo layers have a different amount of classes: 80 and 2",
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			_, err := Parse(strings.NewReader(test.Input))
+			s.EqualError(err, test.Error)
+		})
+	}
+}
+
+func (s *DarknetTestSuite) TestNoYoloLayers() {
+	config, err := Parse(strings.NewReader("[net]\nwidth=416\n"))
+	s.Require().NoError(err)
+	s.Equal([]string{}, config.OutputLayerNames())
+	s.Equal(0, config.Classes())
+}
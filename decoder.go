@@ -0,0 +1,148 @@
+package yolov3
+
+import (
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// Candidate is a candidate detection decoded from the output layers of the net. Fields may be
+// added over time, so decoders should set the fields by name.
+type Candidate struct {
+	// Box is formatted as center x, center y, width and height, normalised to the input size of the net
+	Box [4]float32
+	// Objectness is the confidence that the box contains any object, one for models without an objectness score
+	Objectness float32
+	// Scores are the scores of the classes, which are only valid for the duration of the call
+	Scores []float32
+}
+
+// OutputDecoder decodes the output layers of the net into candidate detections.
+type OutputDecoder interface {
+	// Decode calls fn for each candidate in the outputs.
+	Decode(outputs []gocv.Mat, inputSize image.Point, fn func(Candidate)) error
+	// Classes returns the amount of classes in the outputs.
+	Classes(outputs []gocv.Mat) (int, error)
+}
+
+// DarknetDecoder decodes the outputs of Darknet yolo models, of which each row is formatted as
+// [cx, cy, w, h, objectness, scores...] with normalised coordinates.
+type DarknetDecoder struct{}
+
+// Decode decodes the Darknet output layers.
+func (DarknetDecoder) Decode(outputs []gocv.Mat, _ image.Point, fn func(Candidate)) error {
+	for i := 0; i < len(outputs); i++ {
+		data, rows, cols, err := outputData(outputs[i])
+		if err != nil {
+			return err
+		}
+		if cols < 5 {
+			return fmt.Errorf("output layer %d has %d columns, expected at least 5", i, cols)
+		}
+		for j := 0; j < rows; j++ {
+			row := data[j*cols : (j+1)*cols]
+			fn(Candidate{Box: [4]float32{row[0], row[1], row[2], row[3]}, Objectness: row[4], Scores: row[5:]})
+		}
+	}
+	return nil
+}
+
+// Classes returns the amount of classes in the Darknet output layers.
+func (DarknetDecoder) Classes(outputs []gocv.Mat) (int, error) {
+	_, cols, err := firstOutputShape(outputs)
+	return cols - 5, err
+}
+
+// YoloV5Decoder decodes the outputs of YOLOv5 ONNX exports, shaped [1, N, 5+C] of which each row
+// is formatted as [cx, cy, w, h, objectness, scores...] with coordinates in pixels of the input size.
+type YoloV5Decoder struct{}
+
+// Decode decodes the YOLOv5 output layers, the class scores are weighted by the objectness.
+func (YoloV5Decoder) Decode(outputs []gocv.Mat, inputSize image.Point, fn func(Candidate)) error {
+	width, height := float32(inputSize.X), float32(inputSize.Y)
+	for i := 0; i < len(outputs); i++ {
+		data, rows, cols, err := outputData(outputs[i])
+		if err != nil {
+			return err
+		}
+		if cols < 5 {
+			return fmt.Errorf("output layer %d has %d columns, expected at least 5", i, cols)
+		}
+		scores := make([]float32, cols-5)
+		for j := 0; j < rows; j++ {
+			row := data[j*cols : (j+1)*cols]
+			for k := range scores {
+				scores[k] = row[5+k] * row[4]
+			}
+			box := [4]float32{row[0] / width, row[1] / height, row[2] / width, row[3] / height}
+			fn(Candidate{Box: box, Objectness: row[4], Scores: scores})
+		}
+	}
+	return nil
+}
+
+// Classes returns the amount of classes in the YOLOv5 output layers.
+func (YoloV5Decoder) Classes(outputs []gocv.Mat) (int, error) {
+	_, cols, err := firstOutputShape(outputs)
+	return cols - 5, err
+}
+
+// YoloV8Decoder decodes the outputs of YOLOv8 ONNX exports, shaped [1, 4+C, N] of which each column
+// is formatted as [cx, cy, w, h, scores...] with coordinates in pixels of the input size.
+type YoloV8Decoder struct{}
+
+// Decode decodes the YOLOv8 output layers.
+func (YoloV8Decoder) Decode(outputs []gocv.Mat, inputSize image.Point, fn func(Candidate)) error {
+	width, height := float32(inputSize.X), float32(inputSize.Y)
+	for i := 0; i < len(outputs); i++ {
+		data, rows, cols, err := outputData(outputs[i])
+		if err != nil {
+			return err
+		}
+		if rows < 5 {
+			return fmt.Errorf("output layer %d has %d rows, expected at least 5", i, rows)
+		}
+		scores := make([]float32, rows-4)
+		for j := 0; j < cols; j++ {
+			for k := range scores {
+				scores[k] = data[(4+k)*cols+j]
+			}
+			box := [4]float32{data[j] / width, data[cols+j] / height, data[2*cols+j] / width, data[3*cols+j] / height}
+			fn(Candidate{Box: box, Objectness: 1, Scores: scores})
+		}
+	}
+	return nil
+}
+
+// Classes returns the amount of classes in the YOLOv8 output layers.
+func (YoloV8Decoder) Classes(outputs []gocv.Mat) (int, error) {
+	rows, _, err := firstOutputShape(outputs)
+	return rows - 4, err
+}
+
+// firstOutputShape retrieves the rows and columns of the first output layer.
+func firstOutputShape(outputs []gocv.Mat) (int, int, error) {
+	if len(outputs) == 0 {
+		return 0, 0, fmt.Errorf("net has no outputs")
+	}
+	_, rows, cols, err := outputData(outputs[0])
+	return rows, cols, err
+}
+
+// outputData retrieves the data of an output layer together with its rows and columns,
+// ignoring a leading batch dimension of one.
+func outputData(output gocv.Mat) ([]float32, int, int, error) {
+	data, err := output.DataPtrFloat32()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	size := output.Size()
+	for len(size) > 2 && size[0] == 1 {
+		size = size[1:]
+	}
+	if len(size) != 2 {
+		return nil, 0, 0, fmt.Errorf("unexpected output layer shape: %v", output.Size())
+	}
+	return data, size[0], size[1], nil
+}
@@ -0,0 +1,149 @@
+package yolov3
+
+import (
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *YoloTestSuite) TestDecoders() {
+	tests := []struct {
+		Name         string
+		Decoder      OutputDecoder
+		InputOutputs func() []gocv.Mat
+		Result       []Candidate
+		ExpectError  bool
+	}{
+		{
+			Name:    "Darknet rows",
+			Decoder: DarknetDecoder{},
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{laptopDetection(), coffeeDetection()}
+			},
+			Result: []Candidate{
+				{Box: [4]float32{1, 1, 1, 1}, Scores: []float32{9, 0, 0, 0, 0}},
+				{Box: [4]float32{0, 1, 1, 1}, Scores: []float32{0, 9, 0, 0, 0}},
+			},
+		},
+		{
+			Name:    "Darknet too few columns",
+			Decoder: DarknetDecoder{},
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{gocv.NewMatWithSize(1, 4, gocv.MatTypeCV32F)}
+			},
+			ExpectError: true,
+		},
+		{
+			Name:    "YOLOv5 rows weighted by objectness",
+			Decoder: YoloV5Decoder{},
+			InputOutputs: func() []gocv.Mat {
+				output := gocv.NewMatWithSizes([]int{1, 2, 7}, gocv.MatTypeCV32F)
+				for i, v := range []float32{320, 160, 64, 32, 0.5, 0.8, 0.2} {
+					output.SetFloatAt3(0, 0, i, v)
+				}
+				for i, v := range []float32{64, 64, 640, 640, 1, 0.1, 0.9} {
+					output.SetFloatAt3(0, 1, i, v)
+				}
+				return []gocv.Mat{output}
+			},
+			Result: []Candidate{
+				{Box: [4]float32{0.5, 0.25, 0.1, 0.05}, Objectness: 0.5, Scores: []float32{0.4, 0.1}},
+				{Box: [4]float32{0.1, 0.1, 1, 1}, Objectness: 1, Scores: []float32{0.1, 0.9}},
+			},
+		},
+		{
+			Name:    "YOLOv8 transposed columns",
+			Decoder: YoloV8Decoder{},
+			InputOutputs: func() []gocv.Mat {
+				output := gocv.NewMatWithSizes([]int{1, 6, 2}, gocv.MatTypeCV32F)
+				columns := [][]float32{
+					{320, 160, 64, 32, 0.8, 0.2},
+					{64, 64, 640, 640, 0.1, 0.9},
+				}
+				for j, column := range columns {
+					for i, v := range column {
+						output.SetFloatAt3(0, i, j, v)
+					}
+				}
+				return []gocv.Mat{output}
+			},
+			Result: []Candidate{
+				{Box: [4]float32{0.5, 0.25, 0.1, 0.05}, Objectness: 1, Scores: []float32{0.8, 0.2}},
+				{Box: [4]float32{0.1, 0.1, 1, 1}, Objectness: 1, Scores: []float32{0.1, 0.9}},
+			},
+		},
+		{
+			Name:    "YOLOv8 too few rows",
+			Decoder: YoloV8Decoder{},
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{gocv.NewMatWithSizes([]int{1, 4, 2}, gocv.MatTypeCV32F)}
+			},
+			ExpectError: true,
+		},
+		{
+			Name:    "Unexpected output shape",
+			Decoder: YoloV8Decoder{},
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{gocv.NewMatWithSizes([]int{2, 6, 2}, gocv.MatTypeCV32F)}
+			},
+			ExpectError: true,
+		},
+		{
+			Name:    "Incorrect output type",
+			Decoder: YoloV5Decoder{},
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{gocv.NewMatWithSize(1, 10, gocv.MatTypeCV16S)}
+			},
+			ExpectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			var result []Candidate
+			err := test.Decoder.Decode(test.InputOutputs(), image.Pt(640, 640), func(candidate Candidate) {
+				// The scores are only valid for the duration of the call
+				candidate.Scores = append([]float32{}, candidate.Scores...)
+				result = append(result, candidate)
+			})
+			if test.ExpectError {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Require().Len(result, len(test.Result))
+			for i := range result {
+				s.InDeltaSlice(test.Result[i].Box[:], result[i].Box[:], 1e-6)
+				s.InDelta(test.Result[i].Objectness, result[i].Objectness, 1e-6)
+				s.InDeltaSlice(test.Result[i].Scores, result[i].Scores, 1e-6)
+			}
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestProcessOutputsYoloV8() {
+	output := gocv.NewMatWithSizes([]int{1, 6, 1}, gocv.MatTypeCV32F)
+	for i, v := range []float32{320, 320, 64, 64, 0.1, 0.9} {
+		output.SetFloatAt3(0, i, 0, v)
+	}
+	y := &yoloNet{
+		labels:              labelsFromNames([]string{"laptop", "coffee"}),
+		decoder:             YoloV8Decoder{},
+		DefaultInputWidth:   640,
+		DefaultInputHeight:  640,
+		confidenceThreshold: DefaultConfThreshold,
+		DefaultNMSThreshold: DefaultNMSThreshold,
+	}
+	frame := gocv.NewMatWithSize(100, 200, gocv.MatTypeCV32F)
+	detections, err := y.processOutputs(frame, []gocv.Mat{output}, Filter{})
+	s.Require().NoError(err)
+	s.Equal(withBoxes(image.Pt(200, 100), []ObjectDetection{
+		{
+			ClassID:     1,
+			ClassName:   "coffee",
+			Confidence:  0.9,
+			Objectness:  1,
+			BoundingBox: image.Rect(90, 45, 110, 55),
+		},
+	}), detections)
+}
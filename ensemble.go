@@ -0,0 +1,286 @@
+package yolov3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/internal/nms"
+)
+
+// DefaultEnsembleIoUThreshold is the default minimum intersection over union of boxes of multiple models to be fused.
+const DefaultEnsembleIoUThreshold float32 = 0.55
+
+// EnsembleFusion determines how the detections of the models of an ensemble are merged.
+type EnsembleFusion int
+
+const (
+	// FuseWeightedBoxes merges overlapping boxes of the same class using Weighted Box Fusion. The fused box is
+	// the average of the boxes weighted by their confidence, the confidence is lowered for objects which are
+	// detected by only part of the models.
+	FuseWeightedBoxes EnsembleFusion = iota
+	// FuseNMS keeps the most confident of the overlapping boxes of the same class using non-maximum suppression.
+	FuseNMS
+)
+
+// EnsembleModel is a model of an ensemble.
+type EnsembleModel struct {
+	Net Net
+	// Weight of the detections of the model, defaults to one
+	Weight float32
+	// Labels maps the class names of the model onto the class names of the ensemble. Classes which are
+	// not mapped keep their name.
+	Labels map[string]string
+}
+
+// EnsembleConfig can be used to customise the merging of the detections of an ensemble.
+type EnsembleConfig struct {
+	// Labels are the classes of the ensemble, detections of the models are matched to them by name.
+	// Detections of classes which are not part of the labels, or are disabled, are dropped.
+	Labels []Label
+	// Fusion determines how detections are merged, defaults to Weighted Box Fusion
+	Fusion EnsembleFusion
+	// IoUThreshold is the minimum intersection over union of two boxes of the same class for them to be merged,
+	// defaults to DefaultEnsembleIoUThreshold
+	IoUThreshold float32
+}
+
+// EnsembleNet is a Net which detects objects using multiple models and merges their detections into a single set
+// of detections. The models are run one after another.
+type EnsembleNet struct {
+	detector
+	models []EnsembleModel
+	config EnsembleConfig
+}
+
+// NewEnsembleNet creates an ensemble of given models.
+func NewEnsembleNet(models []EnsembleModel, config EnsembleConfig) (*EnsembleNet, error) {
+	if len(models) == 0 {
+		return nil, fmt.Errorf("at least one model is required")
+	}
+	if len(config.Labels) == 0 {
+		return nil, fmt.Errorf("labels of the ensemble are required")
+	}
+	if config.IoUThreshold <= 0 {
+		config.IoUThreshold = DefaultEnsembleIoUThreshold
+	}
+
+	ensemble := &EnsembleNet{models: []EnsembleModel{}, config: config}
+	ensemble.detector = detector{detect: ensemble.detect}
+	for i, model := range models {
+		if model.Net == nil {
+			return nil, fmt.Errorf("model %d: net is required", i)
+		}
+		if model.Weight < 0 {
+			return nil, fmt.Errorf("model %d: weight must not be negative", i)
+		}
+		if model.Weight == 0 {
+			model.Weight = 1
+		}
+		for name, mapped := range model.Labels {
+			if ensemble.classID(mapped) < 0 {
+				return nil, fmt.Errorf("model %d: class %q is mapped onto unknown class %q", i, name, mapped)
+			}
+		}
+		ensemble.models = append(ensemble.models, model)
+	}
+	return ensemble, nil
+}
+
+// Close closes the nets of all models.
+func (e *EnsembleNet) Close() error {
+	var err error
+	for _, model := range e.models {
+		err = errors.Join(err, model.Net.Close())
+	}
+	return err
+}
+
+// detect detects the objects in the frame using all models, maps them onto the classes of the ensemble
+// and merges them. Only the classes allowed by given filter are reported, if any.
+func (e *EnsembleNet) detect(ctx context.Context, frame gocv.Mat, filter *Filter) ([]ObjectDetection, error) {
+	detections := [][]ObjectDetection{}
+	for i, model := range e.models {
+		modelDetections, err := model.Net.GetDetectionsContext(ctx, frame)
+		if err != nil {
+			return nil, fmt.Errorf("model %d: %w", i, err)
+		}
+		detections = append(detections, e.mapDetections(model, modelDetections, filter))
+	}
+	if e.config.Fusion == FuseNMS {
+		return e.nmsFusion(detections), nil
+	}
+	return e.weightedBoxFusion(detections, image.Pt(frame.Cols(), frame.Rows())), nil
+}
+
+// mapDetections maps the detections of a model onto the classes of the ensemble, dropping
+// detections of unknown, disabled or filtered classes.
+func (e *EnsembleNet) mapDetections(model EnsembleModel, detections []ObjectDetection, filter *Filter) []ObjectDetection {
+	result := []ObjectDetection{}
+	for _, detection := range detections {
+		name := detection.ClassName
+		if mapped, ok := model.Labels[name]; ok {
+			name = mapped
+		}
+		classID := e.classID(name)
+		if classID < 0 {
+			continue
+		}
+		label := e.config.Labels[classID]
+		if !label.Enabled || (filter != nil && !filter.allows(classID, label)) {
+			continue
+		}
+		result = append(result, ObjectDetection{
+			ClassID:       classID,
+			ClassName:     label.Name,
+			BoundingBox:   detection.BoundingBox,
+			Box:           detection.Box,
+			NormalisedBox: detection.NormalisedBox,
+			Confidence:    detection.Confidence,
+			Objectness:    detection.Objectness,
+			DisplayName:   label.DisplayName,
+			Alias:         label.Alias,
+			Group:         label.Group,
+			Color:         label.Color,
+		})
+	}
+	return result
+}
+
+// classID retrieves the id of the class of the ensemble with given name, or -1 if there is none.
+func (e *EnsembleNet) classID(name string) int {
+	for i, label := range e.config.Labels {
+		if label.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// fusionCluster is a cluster of boxes of the same class which are fused into a single box.
+type fusionCluster struct {
+	detection ObjectDetection
+	// minX, minY, maxX & maxY are the coordinates of the fused box
+	minX, minY, maxX, maxY float64
+	// scores are the weighted confidences of the clustered boxes
+	scores []float64
+}
+
+// box retrieves the fused box of the cluster.
+func (c *fusionCluster) box() Box {
+	return Box{MinX: float32(c.minX), MinY: float32(c.minY), MaxX: float32(c.maxX), MaxY: float32(c.maxY)}
+}
+
+// add adds a box with given weighted confidence to the cluster, updating the fused box.
+func (c *fusionCluster) add(box Box, score float64) {
+	total := 0.0
+	for _, s := range c.scores {
+		total += s
+	}
+	average := func(fused float64, v float32) float64 {
+		// Boxes without confidence do not move the fused box
+		if total+score == 0 {
+			return fused
+		}
+		return (fused*total + float64(v)*score) / (total + score)
+	}
+	c.minX, c.minY = average(c.minX, box.MinX), average(c.minY, box.MinY)
+	c.maxX, c.maxY = average(c.maxX, box.MaxX), average(c.maxY, box.MaxY)
+	c.scores = append(c.scores, score)
+}
+
+// weightedBoxFusion merges the detections of all models using Weighted Box Fusion. The confidence of a fused box
+// is the average weighted confidence of its boxes, scaled down when fewer models than the total amount of models
+// contributed to it.
+func (e *EnsembleNet) weightedBoxFusion(detections [][]ObjectDetection, frameSize image.Point) []ObjectDetection {
+	type weightedDetection struct {
+		detection ObjectDetection
+		score     float64
+	}
+	all := []weightedDetection{}
+	totalWeight := 0.0
+	for i, model := range e.models {
+		totalWeight += float64(model.Weight)
+		for _, detection := range detections[i] {
+			all = append(all, weightedDetection{detection: detection, score: float64(detection.Confidence) * float64(model.Weight)})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].score > all[j].score
+	})
+
+	clusters := []*fusionCluster{}
+	for _, d := range all {
+		var match *fusionCluster
+		best := e.config.IoUThreshold
+		for _, cluster := range clusters {
+			if cluster.detection.ClassID != d.detection.ClassID {
+				continue
+			}
+			if iou := cluster.box().intersectionOverUnion(d.detection.box()); iou > best {
+				match, best = cluster, iou
+			}
+		}
+		if match == nil {
+			box := d.detection.box()
+			match = &fusionCluster{
+				detection: d.detection,
+				minX:      float64(box.MinX),
+				minY:      float64(box.MinY),
+				maxX:      float64(box.MaxX),
+				maxY:      float64(box.MaxY),
+			}
+			clusters = append(clusters, match)
+		}
+		match.add(d.detection.box(), d.score)
+	}
+
+	result := []ObjectDetection{}
+	for _, cluster := range clusters {
+		total := 0.0
+		for _, score := range cluster.scores {
+			total += score
+		}
+		models := math.Min(float64(len(cluster.scores)), float64(len(e.models)))
+		detection := cluster.detection
+		detection.setBox(cluster.box(), frameSize)
+		detection.Confidence = float32(total / float64(len(cluster.scores)) * models / totalWeight)
+		result = append(result, detection)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Confidence > result[j].Confidence
+	})
+	return result
+}
+
+// nmsFusion merges the detections of all models by keeping the most confident of the overlapping boxes of the same
+// class, where the confidences are weighted by the weight of the model. The kept detections keep their confidence.
+func (e *EnsembleNet) nmsFusion(detections [][]ObjectDetection) []ObjectDetection {
+	all := []ObjectDetection{}
+	weights := []float32{}
+	for i, model := range e.models {
+		for _, detection := range detections[i] {
+			all = append(all, detection)
+			weights = append(weights, model.Weight)
+		}
+	}
+
+	boxes, scores, classIDs := nmsInput(all)
+	for i := range scores {
+		scores[i] *= weights[i]
+	}
+	kept := nms.PerClass(classIDs, func(_ int, indices []int) []int {
+		return nms.Greedy(boxes, scores, indices, 0, e.config.IoUThreshold, nms.IoU)
+	})
+	nms.SortByScore(scores, kept)
+	result := []ObjectDetection{}
+	for _, i := range kept {
+		result = append(result, all[i])
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+// staticNet is a fake net which always returns the same detections.
+func staticNet(detections ...ObjectDetection) *fakeNet {
+	return &fakeNet{detect: func(context.Context, gocv.Mat) ([]ObjectDetection, error) {
+		return append([]ObjectDetection{}, detections...), nil
+	}}
+}
+
+func (s *YoloTestSuite) TestEnsembleNet() {
+	labels := labelsFromNames([]string{"person", "car", "dog"})
+	labels[2].Enabled = false
+	yolo := func() *fakeNet {
+		return staticNet(
+			ObjectDetection{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(100, 100, 200, 200)},
+			ObjectDetection{ClassID: 2, ClassName: "car", Confidence: 0.9, BoundingBox: image.Rect(300, 300, 400, 400)},
+			ObjectDetection{ClassID: 7, ClassName: "truck", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 10, 10)},
+			ObjectDetection{ClassID: 16, ClassName: "dog", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 10, 10)},
+		)
+	}
+	custom := func() *fakeNet {
+		return staticNet(
+			ObjectDetection{ClassID: 0, ClassName: "human", Confidence: 0.6, BoundingBox: image.Rect(120, 100, 220, 200)},
+		)
+	}
+	tests := []struct {
+		Name   string
+		Config EnsembleConfig
+		Filter Filter
+		Result []ObjectDetection
+	}{
+		{
+			Name:   "Weighted box fusion",
+			Config: EnsembleConfig{Labels: labels},
+			Result: []ObjectDetection{
+				{ClassID: 0, ClassName: "person", Confidence: 0.8, BoundingBox: image.Rect(105, 100, 205, 200)},
+				// Detected by only one of the models
+				{ClassID: 1, ClassName: "car", Confidence: 0.6, BoundingBox: image.Rect(300, 300, 400, 400)},
+			},
+		},
+		{
+			Name:   "NMS fusion",
+			Config: EnsembleConfig{Labels: labels, Fusion: FuseNMS},
+			Result: []ObjectDetection{
+				{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(100, 100, 200, 200)},
+				{ClassID: 1, ClassName: "car", Confidence: 0.9, BoundingBox: image.Rect(300, 300, 400, 400)},
+			},
+		},
+		{
+			Name:   "Filtered",
+			Config: EnsembleConfig{Labels: labels},
+			Filter: Filter{ExcludeNames: []string{"car"}},
+			Result: []ObjectDetection{
+				{ClassID: 0, ClassName: "person", Confidence: 0.8, BoundingBox: image.Rect(105, 100, 205, 200)},
+			},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			ensemble, err := NewEnsembleNet([]EnsembleModel{
+				{Net: yolo(), Weight: 2},
+				{Net: custom(), Labels: map[string]string{"human": "person"}},
+			}, test.Config)
+			s.Require().NoError(err)
+
+			detections, err := ensemble.GetDetectionsWithFilter(gocv.NewMatWithSize(416, 416, gocv.MatTypeCV8UC3), test.Filter)
+			s.Require().NoError(err)
+			s.Require().Len(detections, len(test.Result))
+			for i := range detections {
+				s.Equal(test.Result[i].ClassID, detections[i].ClassID)
+				s.Equal(test.Result[i].ClassName, detections[i].ClassName)
+				s.Equal(test.Result[i].BoundingBox, detections[i].BoundingBox)
+				s.InDelta(test.Result[i].Confidence, detections[i].Confidence, 1e-6)
+			}
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestWeightedBoxFusionSingleModel() {
+	ensemble, err := NewEnsembleNet([]EnsembleModel{{Net: staticNet(
+		ObjectDetection{ClassName: "person", Confidence: 0.8, BoundingBox: image.Rect(0, 0, 100, 100)},
+		ObjectDetection{ClassName: "person", Confidence: 0.4, BoundingBox: image.Rect(10, 0, 110, 100)},
+		ObjectDetection{ClassName: "person", Confidence: 0.4, BoundingBox: image.Rect(500, 0, 600, 100)},
+	)}}, EnsembleConfig{Labels: labelsFromNames([]string{"person"})})
+	s.Require().NoError(err)
+
+	detections, err := ensemble.GetDetections(gocv.NewMatWithSize(416, 416, gocv.MatTypeCV8UC3))
+	s.Require().NoError(err)
+	s.Require().Len(detections, 2)
+	// Boxes of a single model are fused, but their confidence is not scaled up
+	s.Equal(image.Rect(3, 0, 103, 100), detections[0].BoundingBox)
+	s.InDelta(0.6, detections[0].Confidence, 1e-6)
+	s.Equal(image.Rect(500, 0, 600, 100), detections[1].BoundingBox)
+	s.InDelta(0.4, detections[1].Confidence, 1e-6)
+}
+
+func (s *YoloTestSuite) TestNewEnsembleNetErrors() {
+	labels := labelsFromNames([]string{"person"})
+	tests := []struct {
+		Name   string
+		Models []EnsembleModel
+		Config EnsembleConfig
+		Error  string
+	}{
+		{
+			Name:   "No models",
+			Config: EnsembleConfig{Labels: labels},
+			Error:  "at least one model is required",
+		},
+		{
+			Name:   "No labels",
+			Models: []EnsembleModel{{Net: staticNet()}},
+			Error:  "labels of the ensemble are required",
+		},
+		{
+			Name:   "No net",
+			Models: []EnsembleModel{{Net: staticNet()}, {}},
+			Config: EnsembleConfig{Labels: labels},
+			Error:  "model 1: net is required",
+		},
+		{
+			Name:   "Negative weight",
+			Models: []EnsembleModel{{Net: staticNet(), Weight: -1}},
+			Config: EnsembleConfig{Labels: labels},
+			Error:  "model 0: weight must not be negative",
+		},
+		{
+			Name:   "Unknown class",
+			Models: []EnsembleModel{{Net: staticNet(), Labels: map[string]string{"human": "people"}}},
+			Config: EnsembleConfig{Labels: labels},
+			Error:  `model 0: class "human" is mapped onto unknown class "people"`,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			_, err := NewEnsembleNet(test.Models, test.Config)
+			s.EqualError(err, test.Error)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestEnsembleNetError() {
+	broken := &fakeNet{detect: func(context.Context, gocv.Mat) ([]ObjectDetection, error) {
+		return nil, fmt.Errorf("very broken")
+	}}
+	ensemble, err := NewEnsembleNet([]EnsembleModel{{Net: staticNet()}, {Net: broken}}, EnsembleConfig{Labels: labelsFromNames([]string{"person"})})
+	s.Require().NoError(err)
+
+	_, err = ensemble.GetDetections(gocv.NewMatWithSize(416, 416, gocv.MatTypeCV8UC3))
+	s.EqualError(err, "model 1: very broken")
+}
@@ -0,0 +1,49 @@
+package yolov3
+
+import (
+	"context"
+	"slices"
+
+	"gocv.io/x/gocv"
+)
+
+// Filter determines which classes are reported by a net. Classes can be included or excluded by
+// name, id or group, exclusions take precedence over inclusions. The zero value reports all classes.
+type Filter struct {
+	// IncludeNames, IncludeIDs & IncludeGroups, when any of them is set, only classes matching
+	// at least one of them are reported
+	IncludeNames  []string
+	IncludeIDs    []int
+	IncludeGroups []string
+	// ExcludeNames, ExcludeIDs & ExcludeGroups, classes matching any of them are not reported
+	ExcludeNames  []string
+	ExcludeIDs    []int
+	ExcludeGroups []string
+}
+
+// allows determines whether detections of the class with given label are reported.
+func (f Filter) allows(classID int, label Label) bool {
+	if matchesClass(f.ExcludeNames, f.ExcludeIDs, f.ExcludeGroups, classID, label) {
+		return false
+	}
+	if len(f.IncludeNames) == 0 && len(f.IncludeIDs) == 0 && len(f.IncludeGroups) == 0 {
+		return true
+	}
+	return matchesClass(f.IncludeNames, f.IncludeIDs, f.IncludeGroups, classID, label)
+}
+
+// matchesClass determines whether the class matches any of the names, ids or groups.
+func matchesClass(names []string, ids []int, groups []string, classID int, label Label) bool {
+	return slices.Contains(names, label.Name) ||
+		slices.Contains(ids, classID) ||
+		(label.Group != "" && slices.Contains(groups, label.Group))
+}
+
+// detectWithFilter detects the objects in the frame using the net, applying given filter or else
+// the filter of the net.
+func detectWithFilter(ctx context.Context, net Net, frame gocv.Mat, filter *Filter) ([]ObjectDetection, error) {
+	if filter == nil {
+		return net.GetDetectionsContext(ctx, frame)
+	}
+	return net.GetDetectionsWithFilterContext(ctx, frame, *filter)
+}
@@ -0,0 +1,67 @@
+package yolov3
+
+import (
+	"context"
+	"image"
+
+	"github.com/golang/mock/gomock"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+)
+
+func (s *YoloTestSuite) TestGetDetectionsDefaultFilter() {
+	laptop := ObjectDetection{ClassID: 0, Confidence: 9, ClassName: "laptop", BoundingBox: image.Rect(1, 1, 3, 3)}
+	coffee := ObjectDetection{ClassID: 1, Confidence: 9, ClassName: "coffee", BoundingBox: image.Rect(-1, 1, 1, 3)}
+	tests := []struct {
+		Name   string
+		Detect func(y *yoloNet, frame gocv.Mat) ([]ObjectDetection, error)
+		Result []ObjectDetection
+	}{
+		{
+			Name: "Default filter",
+			Detect: func(y *yoloNet, frame gocv.Mat) ([]ObjectDetection, error) {
+				return y.GetDetections(frame)
+			},
+			Result: []ObjectDetection{laptop},
+		},
+		{
+			Name: "Default filter with context",
+			Detect: func(y *yoloNet, frame gocv.Mat) ([]ObjectDetection, error) {
+				return y.GetDetectionsContext(context.Background(), frame)
+			},
+			Result: []ObjectDetection{laptop},
+		},
+		{
+			Name: "Filter per call",
+			Detect: func(y *yoloNet, frame gocv.Mat) ([]ObjectDetection, error) {
+				return y.GetDetectionsWithFilter(frame, Filter{IncludeIDs: []int{1}})
+			},
+			Result: []ObjectDetection{coffee},
+		},
+		{
+			Name: "Empty filter per call",
+			Detect: func(y *yoloNet, frame gocv.Mat) ([]ObjectDetection, error) {
+				return y.GetDetectionsWithFilter(frame, Filter{})
+			},
+			Result: []ObjectDetection{laptop, coffee},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			controller := gomock.NewController(s.T())
+			neuralNetMock := mocks.NewMockNeuralNet(controller)
+			neuralNetMock.EXPECT().SetInput(gomock.Any(), "").Times(1)
+			neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).Return([]gocv.Mat{laptopDetection(), coffeeDetection()}).Times(1)
+
+			y := &yoloNet{
+				net:    neuralNetMock,
+				labels: labelsFromNames([]string{"laptop", "coffee"}),
+				filter: Filter{ExcludeNames: []string{"coffee"}},
+			}
+			detections, err := test.Detect(y, gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+			s.Require().NoError(err)
+			s.Equal(withBoxes(image.Pt(2, 2), test.Result), detections)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+
+	"gocv.io/x/gocv"
+)
+
+// DetectImage detects objects in given image using the net. The bounding boxes of the detections
+// are in the coordinates of the image.
+func DetectImage(net Net, img image.Image) ([]ObjectDetection, error) {
+	return DetectImageContext(context.Background(), net, img)
+}
+
+// DetectImageContext detects objects in given image using the net, unless the context is done.
+// The bounding boxes of the detections are in the coordinates of the image.
+func DetectImageContext(ctx context.Context, net Net, img image.Image) ([]ObjectDetection, error) {
+	frame, err := imageToMat(img)
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer frame.Close()
+
+	detections, err := net.GetDetectionsContext(ctx, frame)
+	if err != nil {
+		return nil, err
+	}
+
+	// The frame starts at the origin, while the bounds of the image might not
+	offset := img.Bounds().Min
+	for i := range detections {
+		if detections[i].Box != (Box{}) {
+			detections[i].Box = detections[i].Box.Add(offset)
+		}
+		detections[i].BoundingBox = detections[i].BoundingBox.Add(offset)
+	}
+	return detections, nil
+}
+
+// DetectBytes detects objects in given encoded image, such as a JPEG or PNG, using the net.
+// The bounding boxes of the detections are in the coordinates of the image.
+func DetectBytes(net Net, data []byte) ([]ObjectDetection, error) {
+	return DetectBytesContext(context.Background(), net, data)
+}
+
+// DetectBytesContext detects objects in given encoded image, such as a JPEG or PNG, using the net,
+// unless the context is done. The bounding boxes of the detections are in the coordinates of the image.
+func DetectBytesContext(ctx context.Context, net Net, data []byte) ([]ObjectDetection, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("image is empty")
+	}
+	frame, err := gocv.IMDecode(data, gocv.IMReadColor)
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer frame.Close()
+	if frame.Empty() {
+		return nil, fmt.Errorf("unable to decode image")
+	}
+
+	return net.GetDetectionsContext(ctx, frame)
+}
+
+// imageToMat converts the image into a BGR Mat, the caller is responsible for closing it.
+func imageToMat(img image.Image) (gocv.Mat, error) {
+	if img == nil || img.Bounds().Empty() {
+		return gocv.Mat{}, fmt.Errorf("image is empty")
+	}
+	return gocv.ImageToMatRGB(contiguousImage(img))
+}
+
+// contiguousImage copies the image into an RGBA image starting at the origin, unless it is an RGBA or
+// NRGBA image of which the pixels are contiguous. gocv reads the pixels of those images without taking
+// the stride into account.
+func contiguousImage(img image.Image) image.Image {
+	bounds := img.Bounds()
+	contiguous := func(pix []uint8, stride int) bool {
+		return stride == 4*bounds.Dx() && len(pix) == stride*bounds.Dy()
+	}
+	switch img := img.(type) {
+	case *image.RGBA:
+		if contiguous(img.Pix, img.Stride) {
+			return img
+		}
+	case *image.NRGBA:
+		if contiguous(img.Pix, img.Stride) {
+			return img
+		}
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
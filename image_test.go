@@ -0,0 +1,162 @@
+package yolov3
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+
+	"gocv.io/x/gocv"
+)
+
+// frameNet is a fake net which detects a single object covering the left half of the frame.
+func frameNet() *fakeNet {
+	return &fakeNet{detect: func(_ context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+		return []ObjectDetection{{
+			ClassName:   "laptop",
+			BoundingBox: image.Rect(0, 0, frame.Cols()/2, frame.Rows()),
+		}}, nil
+	}}
+}
+
+func (s *YoloTestSuite) TestDetectImage() {
+	tests := []struct {
+		Name        string
+		Image       image.Image
+		Result      []ObjectDetection
+		ExpectError bool
+	}{
+		{
+			Name:   "RGBA image",
+			Image:  image.NewRGBA(image.Rect(0, 0, 40, 20)),
+			Result: []ObjectDetection{{ClassName: "laptop", BoundingBox: image.Rect(0, 0, 20, 20)}},
+		},
+		{
+			Name:   "Gray image",
+			Image:  image.NewGray(image.Rect(0, 0, 40, 20)),
+			Result: []ObjectDetection{{ClassName: "laptop", BoundingBox: image.Rect(0, 0, 20, 20)}},
+		},
+		{
+			Name:   "Sub image not starting at the origin",
+			Image:  image.NewRGBA(image.Rect(0, 0, 100, 100)).SubImage(image.Rect(10, 30, 50, 50)),
+			Result: []ObjectDetection{{ClassName: "laptop", BoundingBox: image.Rect(10, 30, 30, 50)}},
+		},
+		{
+			Name:   "NRGBA sub image",
+			Image:  image.NewNRGBA(image.Rect(0, 0, 100, 100)).SubImage(image.Rect(10, 30, 50, 50)),
+			Result: []ObjectDetection{{ClassName: "laptop", BoundingBox: image.Rect(10, 30, 30, 50)}},
+		},
+		{
+			Name:        "Empty image",
+			Image:       image.NewRGBA(image.Rect(0, 0, 0, 0)),
+			ExpectError: true,
+		},
+		{
+			Name:        "No image",
+			ExpectError: true,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			detections, err := DetectImage(frameNet(), test.Image)
+			if test.ExpectError {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Result, detections)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestContiguousImage() {
+	red := color.NRGBA{R: 255, A: 255}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	nrgba.Set(10, 30, red)
+	rgba := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	rgba.Set(10, 30, red)
+	gray := image.NewGray(image.Rect(0, 0, 100, 100))
+	gray.Set(10, 30, color.White)
+
+	tests := []struct {
+		Name   string
+		Image  image.Image
+		Copied bool
+		Pixel  color.Color
+	}{
+		{
+			Name:  "RGBA image",
+			Image: rgba,
+			Pixel: red,
+		},
+		{
+			Name:  "NRGBA image",
+			Image: nrgba,
+			Pixel: red,
+		},
+		{
+			Name:   "RGBA sub image",
+			Image:  rgba.SubImage(image.Rect(10, 30, 50, 50)),
+			Copied: true,
+			Pixel:  red,
+		},
+		{
+			Name:   "NRGBA sub image",
+			Image:  nrgba.SubImage(image.Rect(10, 30, 50, 50)),
+			Copied: true,
+			Pixel:  red,
+		},
+		{
+			Name:   "Sub image of full rows",
+			Image:  nrgba.SubImage(image.Rect(0, 30, 100, 50)),
+			Copied: true,
+			Pixel:  red,
+		},
+		{
+			Name:   "Gray image",
+			Image:  gray.SubImage(image.Rect(10, 30, 50, 50)),
+			Copied: true,
+			Pixel:  color.White,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			result := contiguousImage(test.Image)
+			if test.Copied {
+				s.IsType(&image.RGBA{}, result)
+			} else {
+				s.Same(test.Image, result)
+			}
+			bounds := test.Image.Bounds()
+			s.Equal(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), result.Bounds())
+			pixel := image.Pt(10, 30).Sub(bounds.Min)
+			s.Equal(color.RGBAModel.Convert(test.Pixel), color.RGBAModel.Convert(result.At(pixel.X, pixel.Y)))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestDetectBytes() {
+	var encoded bytes.Buffer
+	s.Require().NoError(png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+
+	detections, err := DetectBytes(frameNet(), encoded.Bytes())
+	s.Require().NoError(err)
+	s.Equal([]ObjectDetection{{ClassName: "laptop", BoundingBox: image.Rect(0, 0, 20, 20)}}, detections)
+
+	_, err = DetectBytes(frameNet(), nil)
+	s.EqualError(err, "image is empty")
+
+	_, err = DetectBytes(frameNet(), []byte("not an image"))
+	s.EqualError(err, "unable to decode image")
+}
+
+func (s *YoloTestSuite) TestDetectImageCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	net := &fakeNet{detect: func(ctx context.Context, _ gocv.Mat) ([]ObjectDetection, error) {
+		return nil, ctx.Err()
+	}}
+	_, err := DetectImageContext(ctx, net, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+	s.ErrorIs(err, context.Canceled)
+}
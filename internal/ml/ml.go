@@ -10,5 +10,7 @@ type NeuralNet interface {
 	SetPreferableTarget(target gocv.NetTargetType) error
 	SetInput(blob gocv.Mat, name string)
 	ForwardLayers(outBlobNames []string) (blobs []gocv.Mat)
+	GetLayerNames() (names []string)
+	GetUnconnectedOutLayers() (ids []int)
 	Close() error
 }
@@ -88,6 +88,34 @@ func (mr *MockNeuralNetMockRecorder) ForwardLayers(outBlobNames interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForwardLayers", reflect.TypeOf((*MockNeuralNet)(nil).ForwardLayers), outBlobNames)
 }
 
+// GetLayerNames mocks base method
+func (m *MockNeuralNet) GetLayerNames() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLayerNames")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetLayerNames indicates an expected call of GetLayerNames
+func (mr *MockNeuralNetMockRecorder) GetLayerNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayerNames", reflect.TypeOf((*MockNeuralNet)(nil).GetLayerNames))
+}
+
+// GetUnconnectedOutLayers mocks base method
+func (m *MockNeuralNet) GetUnconnectedOutLayers() []int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnconnectedOutLayers")
+	ret0, _ := ret[0].([]int)
+	return ret0
+}
+
+// GetUnconnectedOutLayers indicates an expected call of GetUnconnectedOutLayers
+func (mr *MockNeuralNetMockRecorder) GetUnconnectedOutLayers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnconnectedOutLayers", reflect.TypeOf((*MockNeuralNet)(nil).GetUnconnectedOutLayers))
+}
+
 // Close mocks base method
 func (m *MockNeuralNet) Close() error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,158 @@
+// Package nms implements non-maximum suppression of boxes and their scores.
+package nms
+
+import (
+	"math"
+	"sort"
+)
+
+// Box is a box formatted as the left, top, right and bottom coordinates.
+type Box struct {
+	MinX, MinY, MaxX, MaxY float32
+}
+
+// Overlap calculates the overlap of two boxes.
+type Overlap func(a, b Box) float32
+
+// PerClass applies the suppression to the indices of the boxes of each class separately, in order of
+// the first appearance of the class.
+func PerClass(classIDs []int, suppress func(classID int, indices []int) []int) []int {
+	order := []int{}
+	classes := map[int][]int{}
+	for i, classID := range classIDs {
+		if _, ok := classes[classID]; !ok {
+			order = append(order, classID)
+		}
+		classes[classID] = append(classes[classID], i)
+	}
+	kept := []int{}
+	for _, classID := range order {
+		kept = append(kept, suppress(classID, classes[classID])...)
+	}
+	return kept
+}
+
+// Greedy suppresses the boxes at given indices of which the overlap with a box with a higher score
+// exceeds the threshold. Boxes with a score of the score threshold or below are dropped.
+// The kept indices are sorted by score.
+func Greedy(boxes []Box, scores []float32, indices []int, scoreThreshold, threshold float32, overlap Overlap) []int {
+	sorted := []int{}
+	for _, i := range indices {
+		if scores[i] > scoreThreshold {
+			sorted = append(sorted, i)
+		}
+	}
+	SortByScore(scores, sorted)
+
+	kept := []int{}
+	for _, i := range sorted {
+		suppressed := false
+		for _, k := range kept {
+			if overlap(boxes[k], boxes[i]) > threshold {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+// Soft decays the scores of boxes overlapping a box with a higher score, instead of suppressing them.
+// Without the Gaussian decay, scores of boxes of which the intersection over union exceeds the threshold
+// are decayed linearly. The decayed scores are updated in place, boxes of which the score is or decays to
+// the score threshold or below are dropped.
+func Soft(boxes []Box, scores []float32, indices []int, threshold, sigma float32, gaussian bool, scoreThreshold float32) []int {
+	remaining := []int{}
+	for _, i := range indices {
+		if scores[i] > scoreThreshold {
+			remaining = append(remaining, i)
+		}
+	}
+	kept := []int{}
+	for len(remaining) > 0 {
+		best := 0
+		for i := range remaining {
+			if scores[remaining[i]] > scores[remaining[best]] {
+				best = i
+			}
+		}
+		box := boxes[remaining[best]]
+		kept = append(kept, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+
+		decayed := remaining[:0]
+		for _, i := range remaining {
+			iou := float64(IoU(box, boxes[i]))
+			switch {
+			case gaussian:
+				scores[i] *= float32(math.Exp(-iou * iou / float64(sigma)))
+			case iou > float64(threshold):
+				scores[i] *= float32(1 - iou)
+			}
+			if scores[i] > scoreThreshold {
+				decayed = append(decayed, i)
+			}
+		}
+		remaining = decayed
+	}
+	return kept
+}
+
+// SortByScore sorts the indices by their scores in descending order, equal scores are sorted by index.
+func SortByScore(scores []float32, indices []int) {
+	sort.Slice(indices, func(i, j int) bool {
+		a, b := scores[indices[i]], scores[indices[j]]
+		if a != b {
+			return a > b
+		}
+		return indices[i] < indices[j]
+	})
+}
+
+// Area calculates the area of the box, which is zero for empty boxes.
+func Area(b Box) float32 {
+	if b.MaxX <= b.MinX || b.MaxY <= b.MinY {
+		return 0
+	}
+	return (b.MaxX - b.MinX) * (b.MaxY - b.MinY)
+}
+
+// intersection calculates the area of the intersection of two boxes.
+func intersection(a, b Box) float32 {
+	return Area(Box{MinX: max(a.MinX, b.MinX), MinY: max(a.MinY, b.MinY), MaxX: min(a.MaxX, b.MaxX), MaxY: min(a.MaxY, b.MaxY)})
+}
+
+// IoU calculates the area of the intersection of two boxes relative to the area of their union.
+func IoU(a, b Box) float32 {
+	intersection := intersection(a, b)
+	if intersection == 0 {
+		return 0
+	}
+	return intersection / (Area(a) + Area(b) - intersection)
+}
+
+// IoS calculates the area of the intersection of two boxes relative to the area of the smaller box.
+func IoS(a, b Box) float32 {
+	intersection := intersection(a, b)
+	if intersection == 0 {
+		return 0
+	}
+	return intersection / min(Area(a), Area(b))
+}
+
+// DIoU calculates the intersection over union of two boxes, penalised by the squared distance between
+// their centers relative to the squared diagonal of the smallest box enclosing both.
+func DIoU(a, b Box) float32 {
+	width := float64(max(a.MaxX, b.MaxX) - min(a.MinX, b.MinX))
+	height := float64(max(a.MaxY, b.MaxY) - min(a.MinY, b.MinY))
+	diagonal := width*width + height*height
+	if diagonal == 0 {
+		return IoU(a, b)
+	}
+	dx := float64(a.MinX+a.MaxX-b.MinX-b.MaxX) / 2
+	dy := float64(a.MinY+a.MaxY-b.MinY-b.MaxY) / 2
+	return IoU(a, b) - float32((dx*dx+dy*dy)/diagonal)
+}
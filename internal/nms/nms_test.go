@@ -0,0 +1,309 @@
+package nms
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NMSTestSuite struct {
+	suite.Suite
+}
+
+func TestNMSTestSuite(t *testing.T) {
+	suite.Run(t, new(NMSTestSuite))
+}
+
+func (s *NMSTestSuite) TestGreedy() {
+	tests := []struct {
+		Name    string
+		Boxes   []Box
+		Scores  []float32
+		Overlap Overlap
+		Kept    []int
+	}{
+		{
+			Name:    "Suppresses overlapping boxes",
+			Boxes:   []Box{{0, 0, 100, 200}, {20, 20, 120, 200}, {10, 0, 110, 200}},
+			Scores:  []float32{0.9, 0.8, 0.7},
+			Overlap: IoU,
+			Kept:    []int{0},
+		},
+		{
+			Name:    "Sorted by score",
+			Boxes:   []Box{{500, 0, 600, 200}, {20, 20, 120, 200}, {300, 20, 400, 200}},
+			Scores:  []float32{0.2, 0.8, 0.8},
+			Overlap: IoU,
+			Kept:    []int{1, 2, 0},
+		},
+		{
+			Name:    "Below the score threshold",
+			Boxes:   []Box{{500, 0, 600, 200}, {20, 20, 120, 200}},
+			Scores:  []float32{0.05, 0.8},
+			Overlap: IoU,
+			Kept:    []int{1},
+		},
+		{
+			Name: "Sub-pixel boxes",
+			// Intersection over union of 0.5 and just above
+			Boxes:   []Box{{0, 0, 30, 10}, {10, 0, 40, 10}, {9.9, 0, 39.9, 10}},
+			Scores:  []float32{0.9, 0.8, 0.7},
+			Overlap: IoU,
+			Kept:    []int{0, 1},
+		},
+		{
+			Name: "DIoU keeps boxes next to each other",
+			// Intersection over union of 0.54
+			Boxes:   []Box{{0, 0, 100, 20}, {30, 0, 130, 20}},
+			Scores:  []float32{0.9, 0.8},
+			Overlap: DIoU,
+			Kept:    []int{0, 1},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			indices := []int{}
+			for i := range test.Boxes {
+				indices = append(indices, i)
+			}
+			s.Equal(test.Kept, Greedy(test.Boxes, test.Scores, indices, 0.1, 0.5, test.Overlap))
+		})
+	}
+}
+
+func (s *NMSTestSuite) TestSoft() {
+	tests := []struct {
+		Name     string
+		Gaussian bool
+		Boxes    []Box
+		Scores   []float32
+		Kept     []int
+		Decayed  []float32
+	}{
+		{
+			Name: "Linear decays the scores of overlapping boxes",
+			// Intersection over union of 0.6 and 0.25, below the threshold
+			Boxes:   []Box{{0, 0, 100, 200}, {25, 0, 125, 200}, {60, 0, 160, 200}},
+			Scores:  []float32{0.9, 0.8, 0.7},
+			Kept:    []int{0, 2, 1},
+			Decayed: []float32{0.9, 0.8 * 0.4, 0.7},
+		},
+		{
+			Name:     "Gaussian decays the scores of all overlapping boxes",
+			Gaussian: true,
+			Boxes:    []Box{{0, 0, 100, 200}, {25, 0, 125, 200}, {500, 0, 600, 200}},
+			Scores:   []float32{0.9, 0.8, 0.2},
+			Kept:     []int{0, 1, 2},
+			// exp(-0.6 * 0.6 / 0.5)
+			Decayed: []float32{0.9, 0.8 * 0.48675226, 0.2},
+		},
+		{
+			Name: "Linear drops boxes decayed below the threshold",
+			// Intersection over union of 0.9
+			Boxes:   []Box{{0, 0, 100, 200}, {0, 0, 100, 180}},
+			Scores:  []float32{0.9, 0.8},
+			Kept:    []int{0},
+			Decayed: []float32{0.9, 0.8 * 0.1},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			indices := []int{}
+			for i := range test.Boxes {
+				indices = append(indices, i)
+			}
+			kept := Soft(test.Boxes, test.Scores, indices, 0.5, 0.5, test.Gaussian, 0.1)
+			s.Equal(test.Kept, kept)
+			s.InDeltaSlice(test.Decayed, test.Scores, 1e-6)
+		})
+	}
+}
+
+func (s *NMSTestSuite) TestPerClass() {
+	classIDs := []int{2, 0, 2, 1, 0}
+	calls := [][]int{}
+	kept := PerClass(classIDs, func(classID int, indices []int) []int {
+		calls = append(calls, append([]int{classID}, indices...))
+		return indices[:1]
+	})
+	s.Equal([][]int{{2, 0, 2}, {0, 1, 4}, {1, 3}}, calls)
+	s.Equal([]int{0, 1, 3}, kept)
+}
+
+func (s *NMSTestSuite) TestIoU() {
+	tests := []struct {
+		Name     string
+		A        Box
+		B        Box
+		Expected float32
+	}{
+		{
+			Name:     "Same box",
+			A:        Box{0, 0, 100, 100},
+			B:        Box{0, 0, 100, 100},
+			Expected: 1,
+		},
+		{
+			Name:     "Shifted box",
+			A:        Box{0, 0, 100, 20},
+			B:        Box{30, 0, 130, 20},
+			Expected: 1400.0 / 2600,
+		},
+		{
+			Name:     "Sub-pixel box",
+			A:        Box{0, 0, 1.5, 1},
+			B:        Box{0.5, 0, 2, 1},
+			Expected: 0.5,
+		},
+		{
+			Name:     "Touching boxes",
+			A:        Box{0, 0, 10, 10},
+			B:        Box{10, 0, 20, 10},
+			Expected: 0,
+		},
+		{
+			Name:     "Empty boxes",
+			A:        Box{},
+			B:        Box{},
+			Expected: 0,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.InDelta(test.Expected, IoU(test.A, test.B), 1e-6)
+		})
+	}
+}
+
+func (s *NMSTestSuite) TestIoS() {
+	s.InDelta(float32(0.5), IoS(Box{0, 0, 10, 10}, Box{5, 0, 20, 10}), 1e-6)
+	s.InDelta(float32(1), IoS(Box{0, 0, 100, 100}, Box{10, 10, 20, 20}), 1e-6)
+	s.InDelta(float32(0.54), IoS(Box{0, 0, 10, 10}, Box{4.6, 0, 20, 10}), 1e-6)
+	s.Zero(IoS(Box{0, 0, 10, 10}, Box{10, 0, 20, 10}))
+}
+
+func (s *NMSTestSuite) TestDIoU() {
+	tests := []struct {
+		Name     string
+		A        Box
+		B        Box
+		Expected float32
+	}{
+		{
+			Name:     "Same box",
+			A:        Box{0, 0, 100, 100},
+			B:        Box{0, 0, 100, 100},
+			Expected: 1,
+		},
+		{
+			Name:     "Same center",
+			A:        Box{0, 0, 100, 100},
+			B:        Box{10, 10, 90, 90},
+			Expected: 0.64,
+		},
+		{
+			Name: "Shifted box",
+			A:    Box{0, 0, 100, 20},
+			B:    Box{30, 0, 130, 20},
+			// 1400 / 2600 - 30² / (130² + 20²)
+			Expected: 0.48643845,
+		},
+		{
+			Name: "Distant boxes",
+			A:    Box{0, 0, 10, 10},
+			B:    Box{20, 0, 30, 10},
+			// 0 - 20² / (30² + 10²)
+			Expected: -0.4,
+		},
+		{
+			Name:     "Empty boxes",
+			A:        Box{},
+			B:        Box{},
+			Expected: 0,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.InDelta(test.Expected, DIoU(test.A, test.B), 1e-6)
+		})
+	}
+}
+
+// FuzzNonMaxSuppression verifies that the result of each suppression is sorted by score, deterministic
+// and, apart from Soft-NMS, does not contain overlapping boxes which should have been suppressed.
+func FuzzNonMaxSuppression(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 100, 200, 230, 0, 10, 0, 100, 200, 200, 1, 20, 20, 100, 180, 220})
+	f.Add([]byte{0, 0, 0, 100, 20, 230, 0, 30, 0, 100, 20, 200, 0, 0, 0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		const scoreThreshold, threshold = 0.1, 0.5
+		boxes := []Box{}
+		scores := []float32{}
+		classIDs := []int{}
+		for i := 0; i+6 <= len(data); i += 6 {
+			x, y := float32(data[i+1]), float32(data[i+2])
+			boxes = append(boxes, Box{MinX: x, MinY: y, MaxX: x + float32(data[i+3]), MaxY: y + float32(data[i+4])})
+			scores = append(scores, float32(data[i+5])/math.MaxUint8)
+			classIDs = append(classIDs, int(data[i]%3))
+		}
+
+		suppressions := map[string]func(scores []float32) []int{
+			"per class": func(scores []float32) []int {
+				return PerClass(classIDs, func(_ int, indices []int) []int {
+					return Greedy(boxes, scores, indices, scoreThreshold, threshold, IoU)
+				})
+			},
+			"class agnostic": func(scores []float32) []int {
+				indices := []int{}
+				for i := range boxes {
+					indices = append(indices, i)
+				}
+				return Greedy(boxes, scores, indices, scoreThreshold, threshold, IoU)
+			},
+			"soft linear": func(scores []float32) []int {
+				return PerClass(classIDs, func(_ int, indices []int) []int {
+					return Soft(boxes, scores, indices, threshold, 0.5, false, scoreThreshold)
+				})
+			},
+			"soft gaussian": func(scores []float32) []int {
+				return PerClass(classIDs, func(_ int, indices []int) []int {
+					return Soft(boxes, scores, indices, threshold, 0.5, true, scoreThreshold)
+				})
+			},
+			"diou": func(scores []float32) []int {
+				return PerClass(classIDs, func(_ int, indices []int) []int {
+					return Greedy(boxes, scores, indices, scoreThreshold, threshold, DIoU)
+				})
+			},
+		}
+		for name, suppress := range suppressions {
+			resultScores := append([]float32{}, scores...)
+			result := suppress(resultScores)
+			SortByScore(resultScores, result)
+			againScores := append([]float32{}, scores...)
+			again := suppress(againScores)
+			SortByScore(againScores, again)
+			if len(result) > len(boxes) {
+				t.Fatalf("%s: %d boxes out of %d", name, len(result), len(boxes))
+			}
+			if !reflect.DeepEqual(result, again) || !reflect.DeepEqual(resultScores, againScores) {
+				t.Fatalf("%s: result is not deterministic", name)
+			}
+			for i, k := range result {
+				if resultScores[k] <= scoreThreshold {
+					t.Fatalf("%s: score %f below threshold", name, resultScores[k])
+				}
+				if i > 0 && resultScores[k] > resultScores[result[i-1]] {
+					t.Fatalf("%s: result is not sorted by score", name)
+				}
+				for _, j := range result[:i] {
+					sameClass := classIDs[k] == classIDs[j]
+					if ((name == "per class" && sameClass) || name == "class agnostic") && IoU(boxes[k], boxes[j]) > threshold {
+						t.Fatalf("%s: overlapping boxes %v and %v are not suppressed", name, boxes[k], boxes[j])
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package yolov3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Label contains the metadata of a class which can be detected by the net.
+type Label struct {
+	// Name of the class, used for filtering detections
+	Name string
+	// DisplayName used when drawing detections, defaults to the name
+	DisplayName string
+	// Alias is an alternative name of the class
+	Alias string
+	// Group the class belongs to, e.g. "vehicle"
+	Group string
+	// Color used when drawing detections, the zero value results in the default color
+	Color color.RGBA
+	// Enabled determines whether detections of the class are reported
+	Enabled bool
+}
+
+// labelEntry is a label as written in a JSON or YAML label file.
+type labelEntry struct {
+	Name        string `json:"name" yaml:"name"`
+	DisplayName string `json:"display_name" yaml:"display_name"`
+	Alias       string `json:"alias" yaml:"alias"`
+	Group       string `json:"group" yaml:"group"`
+	Color       string `json:"color" yaml:"color"`
+	Enabled     *bool  `json:"enabled" yaml:"enabled"`
+}
+
+// LoadLabels loads the labels from given path. Files with a .json, .yaml or .yml extension
+// contain a list of labels, for example:
+//
+//	[
+//		{"name": "person", "display_name": "Person", "color": "#ff0000", "group": "people"},
+//		{"name": "bicycle", "alias": "bike", "enabled": false}
+//	]
+//
+// Any other file is read as a plain coconames file, containing a class name per line.
+func LoadLabels(path string) ([]Label, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseLabels(content, filepath.Ext(path))
+}
+
+// parseLabels parses the content of a label file. The format is determined by the extension
+// of the file, or by the content itself when the extension is unknown.
+func parseLabels(content []byte, ext string) ([]Label, error) {
+	entries := []labelEntry{}
+	switch labelFormat(content, ext) {
+	case "json":
+		err := json.Unmarshal(content, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse labels: %w", err)
+		}
+	case "yaml":
+		err := yaml.Unmarshal(content, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse labels: %w", err)
+		}
+	default:
+		return labelsFromNames(parseCocoNames(content)), nil
+	}
+
+	labels := []Label{}
+	for i, entry := range entries {
+		label, err := entry.label()
+		if err != nil {
+			return nil, fmt.Errorf("label %d: %w", i, err)
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// labelFormat determines the format of a label file.
+func labelFormat(content []byte, ext string) string {
+	switch strings.ToLower(ext) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".names", ".txt":
+		return "names"
+	}
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("[")) {
+		return "json"
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "- ") {
+			return "yaml"
+		}
+		break
+	}
+	return "names"
+}
+
+// label converts the entry into a label.
+func (e labelEntry) label() (Label, error) {
+	if strings.TrimSpace(e.Name) == "" {
+		return Label{}, fmt.Errorf("name is required")
+	}
+	label := Label{
+		Name:        strings.TrimSpace(e.Name),
+		DisplayName: e.DisplayName,
+		Alias:       e.Alias,
+		Group:       e.Group,
+		Enabled:     e.Enabled == nil || *e.Enabled,
+	}
+	if e.Color != "" {
+		var err error
+		label.Color, err = parseColor(e.Color)
+		if err != nil {
+			return Label{}, err
+		}
+	}
+	return label, nil
+}
+
+// parseColor parses a hex color formatted as #rrggbb.
+func parseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, expected #rrggbb", s)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, expected #rrggbb", s)
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}, nil
+}
+
+// labelsFromNames creates enabled labels without additional metadata for given class names.
+func labelsFromNames(names []string) []Label {
+	labels := []Label{}
+	for _, name := range names {
+		labels = append(labels, Label{Name: name, Enabled: true})
+	}
+	return labels
+}
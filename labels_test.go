@@ -0,0 +1,128 @@
+package yolov3
+
+import (
+	"image"
+	"image/color"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *YoloTestSuite) TestParseLabels() {
+	tests := []struct {
+		Name        string
+		Input       string
+		Ext         string
+		Expected    []Label
+		ExpectError bool
+	}{
+		{
+			Name:     "plain names",
+			Input:    "laptop\ncoffee\n",
+			Ext:      ".names",
+			Expected: []Label{{Name: "laptop", Enabled: true}, {Name: "coffee", Enabled: true}},
+		},
+		{
+			Name:  "json",
+			Input: `[{"name": "laptop", "display_name": "Laptop", "color": "#ff8000"}, {"name": "coffee", "alias": "joe", "group": "drinks", "enabled": false}]`,
+			Ext:   ".json",
+			Expected: []Label{
+				{Name: "laptop", DisplayName: "Laptop", Color: color.RGBA{R: 255, G: 128, A: 255}, Enabled: true},
+				{Name: "coffee", Alias: "joe", Group: "drinks"},
+			},
+		},
+		{
+			Name:  "yaml",
+			Input: "- name: laptop\n  group: electronics\n- name: coffee\n  enabled: true\n",
+			Ext:   ".yml",
+			Expected: []Label{
+				{Name: "laptop", Group: "electronics", Enabled: true},
+				{Name: "coffee", Enabled: true},
+			},
+		},
+		{
+			Name:     "json detected from content",
+			Input:    ` [{"name": "laptop"}]`,
+			Expected: []Label{{Name: "laptop", Enabled: true}},
+		},
+		{
+			Name:     "yaml detected from content",
+			Input:    "# labels\n- name: laptop\n",
+			Expected: []Label{{Name: "laptop", Enabled: true}},
+		},
+		{
+			Name:     "names detected from content",
+			Input:    "# labels\nlaptop\n",
+			Expected: []Label{{Name: "laptop", Enabled: true}},
+		},
+		{
+			Name:        "invalid color",
+			Input:       `[{"name": "laptop", "color": "green"}]`,
+			Ext:         ".json",
+			ExpectError: true,
+		},
+		{
+			Name:        "missing name",
+			Input:       "- display_name: Laptop\n",
+			Ext:         ".yaml",
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid json",
+			Input:       `[{"name": }]`,
+			Ext:         ".json",
+			ExpectError: true,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			labels, err := parseLabels([]byte(test.Input), test.Ext)
+			if test.ExpectError {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Expected, labels)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestLoadLabels() {
+	labels, err := LoadLabels("testdata/laptop_coffee.yaml")
+	s.Require().NoError(err)
+	s.Equal([]Label{
+		{
+			Name:        "laptop",
+			DisplayName: "Laptop",
+			Alias:       "notebook",
+			Group:       "electronics",
+			Color:       color.RGBA{G: 255, A: 255},
+			Enabled:     true,
+		},
+		{Name: "coffee", Group: "drinks"},
+	}, labels)
+
+	_, err = LoadLabels("testdata/unknown.yaml")
+	s.Error(err)
+}
+
+func (s *YoloTestSuite) TestProcessOutputsLabelMetadata() {
+	labels, err := LoadLabels("testdata/laptop_coffee.yaml")
+	s.Require().NoError(err)
+	y := &yoloNet{labels: labels}
+
+	// Detections of the disabled coffee class are left out
+	detections, err := y.processOutputs(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F), []gocv.Mat{laptopDetection(), coffeeDetection()}, Filter{})
+	s.Require().NoError(err)
+	s.Equal(withBoxes(image.Pt(2, 2), []ObjectDetection{
+		{
+			ClassID:     0,
+			ClassName:   "laptop",
+			BoundingBox: image.Rect(1, 1, 3, 3),
+			Confidence:  9,
+			DisplayName: "Laptop",
+			Alias:       "notebook",
+			Group:       "electronics",
+			Color:       color.RGBA{G: 255, A: 255},
+		},
+	}), detections)
+}
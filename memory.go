@@ -0,0 +1,86 @@
+package yolov3
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/internal/ml"
+)
+
+// NewNetFromBytes creates new yolo net from the given weights, net config and coconames contents.
+// The net config can be left empty for models which contain their own topology, such as ONNX models.
+// The coconames contents can also be a JSON or YAML list of labels, see LoadLabels.
+func NewNetFromBytes(weights, netConfig, cocoNames []byte, config Config) (Net, error) {
+	return newNetFromBytes(weights, netConfig, cocoNames, "", config)
+}
+
+// newNetFromBytes creates new yolo net from the contents of the model files, see createNet.
+func newNetFromBytes(weights, netConfig, cocoNames []byte, cocoNamesExt string, config Config) (Net, error) {
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("net weights are empty")
+	}
+
+	return createNet(netConfig, cocoNames, cocoNamesExt, config, func(config Config) (ml.NeuralNet, error) {
+		return config.NewNetFromBytes(config.Framework, weights, netConfig)
+	})
+}
+
+// NewNetFromReader creates new yolo net by reading the weights, net config and coconames from given readers.
+// The net config reader can be nil for models which contain their own topology, such as ONNX models.
+func NewNetFromReader(weights, netConfig, cocoNames io.Reader, config Config) (Net, error) {
+	weightsContent, err := io.ReadAll(weights)
+	if err != nil {
+		return nil, err
+	}
+
+	var netConfigContent []byte
+	if netConfig != nil {
+		netConfigContent, err = io.ReadAll(netConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cocoNamesContent, err := io.ReadAll(cocoNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNetFromBytes(weightsContent, netConfigContent, cocoNamesContent, config)
+}
+
+// NewNetFromFS creates new yolo net from the given paths in a file system, such as an embed.FS.
+// The config path can be left empty for models which contain their own topology, such as ONNX models.
+func NewNetFromFS(fsys fs.FS, weightsPath, configPath, cocoNamePath string, config Config) (Net, error) {
+	weights, err := fs.ReadFile(fsys, weightsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var netConfig []byte
+	if configPath != "" {
+		netConfig, err = fs.ReadFile(fsys, configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cocoNames, err := fs.ReadFile(fsys, cocoNamePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNetFromBytes(weights, netConfig, cocoNames, config)
+}
+
+// initializeNetFromBytes default method for creating neural network from memory, leveraging gocv.
+func initializeNetFromBytes(framework string, weights, netConfig []byte) (ml.NeuralNet, error) {
+	net, err := gocv.ReadNetBytes(framework, weights, netConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &net, nil
+}
@@ -0,0 +1,404 @@
+package yolov3
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"testing/fstest"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/internal/ml"
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, fmt.Errorf("very broken")
+}
+
+// newNetFromBytesMock creates a neural net mock, which returns the given output when the
+// net is forwarded for verifying the class names of models without a Darknet net config.
+// Its output layers are discovered as a single output layer.
+func (s *YoloTestSuite) newNetFromBytesMock(expectedFramework string, output func() gocv.Mat) func(string, []byte, []byte) (ml.NeuralNet, error) {
+	return func(framework string, weights, netConfig []byte) (ml.NeuralNet, error) {
+		s.Equal(expectedFramework, framework)
+		s.Equal([]byte("weights"), weights)
+		controller := gomock.NewController(s.T())
+		neuralNetMock := mocks.NewMockNeuralNet(controller)
+		neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+		neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+		neuralNetMock.EXPECT().GetLayerNames().Return([]string{"output"}).AnyTimes()
+		neuralNetMock.EXPECT().GetUnconnectedOutLayers().Return([]int{1}).AnyTimes()
+		neuralNetMock.EXPECT().SetInput(gomock.Any(), gomock.Any()).AnyTimes()
+		neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).DoAndReturn(func([]string) []gocv.Mat {
+			return []gocv.Mat{output()}
+		}).AnyTimes()
+		return neuralNetMock, nil
+	}
+}
+
+// cocoOutput creates an empty Darknet output layer for the 80 coco classes.
+func cocoOutput() gocv.Mat {
+	return gocv.NewMatWithSize(1, 85, gocv.MatTypeCV32F)
+}
+
+func (s *YoloTestSuite) TestNewNetFromBytes() {
+	config := DefaultConfig()
+	config.NewNetFromBytes = s.newNetFromBytesMock("darknet", cocoOutput)
+
+	net, err := NewNetFromBytes([]byte("weights"), s.readFile("data/yolov3/yolov3.cfg"), s.readFile("data/yolov3/coco.names"), config)
+	s.Require().NoError(err)
+	yoloNet := net.(*yoloNet)
+	s.Equal(80, len(yoloNet.labels))
+	s.Equal([]string{"yolo_82", "yolo_94", "yolo_106"}, yoloNet.outputLayers)
+}
+
+func (s *YoloTestSuite) TestNewNetFromBytesInvalidNetConfig() {
+	_, err := NewNetFromBytes([]byte("weights"), []byte("width=416"), []byte("laptop\ncoffee"), DefaultConfig())
+	s.EqualError(err, `line 1: option "width" outside of a section`)
+
+	_, err = NewNetFromBytes([]byte("weights"), s.readFile("testdata/yolov4.cfg"), []byte("laptop\ncoffee"), DefaultConfigForModel(ModelYoloV3))
+	s.ErrorContains(err, "net config does not match model kind yolov3")
+}
+
+func (s *YoloTestSuite) TestNewNetFromBytesInputFromNetConfig() {
+	netConfig := s.readFile("data/yolov3/yolov3.cfg")
+	tests := []struct {
+		Name          string
+		NetConfig     []byte
+		InputSize     image.Point
+		ExpectedSize  image.Point
+		ExpectedError error
+	}{
+		{
+			Name:         "Input size of the net config",
+			NetConfig:    bytes.Replace(bytes.Replace(netConfig, []byte("width=416"), []byte("width=608"), 1), []byte("height=416"), []byte("height=320"), 1),
+			ExpectedSize: image.Pt(608, 320),
+		},
+		{
+			Name:         "Explicit input size",
+			NetConfig:    netConfig,
+			InputSize:    image.Pt(320, 320),
+			ExpectedSize: image.Pt(320, 320),
+		},
+		{
+			Name:          "Unsupported input channels",
+			NetConfig:     bytes.Replace(netConfig, []byte("channels=3"), []byte("channels=1"), 1),
+			ExpectedError: fmt.Errorf("net config expects 1 input channels, but frames have 3 channels"),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			config := DefaultConfig()
+			config.InputWidth, config.InputHeight = test.InputSize.X, test.InputSize.Y
+			config.NewNetFromBytes = s.newNetFromBytesMock("darknet", cocoOutput)
+
+			net, err := NewNetFromBytes([]byte("weights"), test.NetConfig, s.readFile("data/yolov3/coco.names"), config)
+			if test.ExpectedError != nil {
+				s.EqualError(err, test.ExpectedError.Error())
+				return
+			}
+			s.Require().NoError(err)
+			yoloNet := net.(*yoloNet)
+			s.Equal(test.ExpectedSize, image.Pt(yoloNet.DefaultInputWidth, yoloNet.DefaultInputHeight))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestNewNetFromBytesOnnxFramework() {
+	config := DefaultConfigForModel(ModelYoloV8)
+	config.OutputLayers = []string{"output0"}
+	config.NewNetFromBytes = s.newNetFromBytesMock("onnx", func() gocv.Mat {
+		return gocv.NewMatWithSizes([]int{1, 6, 1}, gocv.MatTypeCV32F)
+	})
+
+	_, err := NewNetFromBytes([]byte("weights"), nil, []byte("laptop\ncoffee"), config)
+	s.Require().NoError(err)
+}
+
+func (s *YoloTestSuite) TestNewNetFromBytesClassNamesMismatch() {
+	config := DefaultConfigForModel(ModelYoloV8)
+	config.OutputLayers = []string{"output0"}
+	config.NewNetFromBytes = func(string, []byte, []byte) (ml.NeuralNet, error) {
+		controller := gomock.NewController(s.T())
+		neuralNetMock := mocks.NewMockNeuralNet(controller)
+		neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+		neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+		neuralNetMock.EXPECT().SetInput(gomock.Any(), "").Times(1)
+		neuralNetMock.EXPECT().ForwardLayers([]string{"output0"}).Return([]gocv.Mat{
+			gocv.NewMatWithSizes([]int{1, 84, 1}, gocv.MatTypeCV32F),
+		}).Times(1)
+		neuralNetMock.EXPECT().Close().Return(nil).Times(1)
+		return neuralNetMock, nil
+	}
+
+	_, err := NewNetFromBytes([]byte("weights"), nil, []byte("laptop\ncoffee"), config)
+	s.EqualError(err, "net outputs 80 classes, but 2 class names are provided")
+}
+
+func (s *YoloTestSuite) TestNewNetFromBytesRegionNetConfig() {
+	// YOLOv2 configs contain a [region] section instead of [yolo] sections
+	netConfig := []byte("[net]\nwidth=416\nheight=416\nchannels=3\n\n[convolutional]\nfilters=425\n\n[region]\nclasses=80\nnum=5\n")
+	tests := []struct {
+		Name          string
+		CocoNames     []byte
+		ExpectedError error
+	}{
+		{
+			Name:      "Class names match the output of the net",
+			CocoNames: s.readFile("data/yolov3/coco.names"),
+		},
+		{
+			Name:          "Class names do not match the output of the net",
+			CocoNames:     []byte("laptop\ncoffee"),
+			ExpectedError: fmt.Errorf("net outputs 80 classes, but 2 class names are provided"),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			config := DefaultConfig()
+			config.NewNetFromBytes = func(framework string, weights, netConfig []byte) (ml.NeuralNet, error) {
+				net, err := s.newNetFromBytesMock("darknet", cocoOutput)(framework, weights, netConfig)
+				if test.ExpectedError != nil {
+					net.(*mocks.MockNeuralNet).EXPECT().Close().Return(nil).Times(1)
+				}
+				return net, err
+			}
+
+			net, err := NewNetFromBytes([]byte("weights"), netConfig, test.CocoNames, config)
+			if test.ExpectedError != nil {
+				s.EqualError(err, test.ExpectedError.Error())
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal([]string{"output"}, net.(*yoloNet).outputLayers)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestUnableToCreateNewNetFromBytes() {
+	tests := []struct {
+		Name            string
+		Weights         []byte
+		Config          Config
+		NewNetFromBytes func(string, []byte, []byte) (ml.NeuralNet, error)
+		Error           error
+	}{
+		{
+			Name:  "Empty weights",
+			Error: fmt.Errorf("net weights are empty"),
+		},
+		{
+			Name:    "Unknown model kind",
+			Weights: []byte("weights"),
+			Config:  Config{ModelKind: ModelKind(99)},
+			Error:   fmt.Errorf("unknown model kind: ModelKind(99)"),
+		},
+		{
+			Name:    "Unable to read net",
+			Weights: []byte("weights"),
+			NewNetFromBytes: func(string, []byte, []byte) (ml.NeuralNet, error) {
+				return nil, fmt.Errorf("very broken")
+			},
+			Error: fmt.Errorf("very broken"),
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			test.Config.NewNetFromBytes = test.NewNetFromBytes
+			_, err := NewNetFromBytes(test.Weights, nil, []byte("laptop"), test.Config)
+			s.Equal(test.Error, err)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestNewNetFromReader() {
+	tests := []struct {
+		Name      string
+		Weights   io.Reader
+		NetConfig io.Reader
+		CocoNames io.Reader
+		ExpectErr bool
+	}{
+		{
+			Name:      "All readers provided",
+			Weights:   bytes.NewBufferString("weights"),
+			NetConfig: bytes.NewBuffer(s.readFile("data/yolov3/yolov3.cfg")),
+			CocoNames: bytes.NewBuffer(s.readFile("data/yolov3/coco.names")),
+		},
+		{
+			Name:      "No net config reader",
+			Weights:   bytes.NewBufferString("weights"),
+			CocoNames: bytes.NewBuffer(s.readFile("data/yolov3/coco.names")),
+		},
+		{
+			Name:      "Unable to read weights",
+			Weights:   failingReader{},
+			CocoNames: bytes.NewBuffer(s.readFile("data/yolov3/coco.names")),
+			ExpectErr: true,
+		},
+		{
+			Name:      "Unable to read net config",
+			Weights:   bytes.NewBufferString("weights"),
+			NetConfig: failingReader{},
+			CocoNames: bytes.NewBuffer(s.readFile("data/yolov3/coco.names")),
+			ExpectErr: true,
+		},
+		{
+			Name:      "Unable to read coco names",
+			Weights:   bytes.NewBufferString("weights"),
+			CocoNames: failingReader{},
+			ExpectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			config := DefaultConfig()
+			config.NewNetFromBytes = s.newNetFromBytesMock("darknet", cocoOutput)
+			net, err := NewNetFromReader(test.Weights, test.NetConfig, test.CocoNames, config)
+			if test.ExpectErr {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(80, len(net.(*yoloNet).labels))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestNewNetFromFS() {
+	fsys := fstest.MapFS{
+		"yolov3.weights": &fstest.MapFile{Data: []byte("weights")},
+		"yolov3.cfg":     &fstest.MapFile{Data: s.readFile("data/yolov3/yolov3.cfg")},
+		"coco.names":     &fstest.MapFile{Data: s.readFile("data/yolov3/coco.names")},
+	}
+	tests := []struct {
+		Name         string
+		WeightsPath  string
+		ConfigPath   string
+		CocoNamePath string
+		ExpectErr    bool
+	}{
+		{
+			Name:         "All files present",
+			WeightsPath:  "yolov3.weights",
+			ConfigPath:   "yolov3.cfg",
+			CocoNamePath: "coco.names",
+		},
+		{
+			Name:         "Without config path",
+			WeightsPath:  "yolov3.weights",
+			CocoNamePath: "coco.names",
+		},
+		{
+			Name:         "Non existent weights path",
+			WeightsPath:  "notexistent",
+			ConfigPath:   "yolov3.cfg",
+			CocoNamePath: "coco.names",
+			ExpectErr:    true,
+		},
+		{
+			Name:         "Non existent config path",
+			WeightsPath:  "yolov3.weights",
+			ConfigPath:   "notexistent",
+			CocoNamePath: "coco.names",
+			ExpectErr:    true,
+		},
+		{
+			Name:         "Non existent coco names path",
+			WeightsPath:  "yolov3.weights",
+			ConfigPath:   "yolov3.cfg",
+			CocoNamePath: "notexistent",
+			ExpectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			config := DefaultConfig()
+			config.NewNetFromBytes = s.newNetFromBytesMock("darknet", cocoOutput)
+			net, err := NewNetFromFS(fsys, test.WeightsPath, test.ConfigPath, test.CocoNamePath, config)
+			if test.ExpectErr {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(80, len(net.(*yoloNet).labels))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestNewNetWithConfigFromPaths() {
+	config := DefaultConfig()
+	// Nets created from paths are loaded from the paths, without reading the weights into memory
+	config.NewNetFromBytes = func(string, []byte, []byte) (ml.NeuralNet, error) {
+		s.Fail("net loaded from memory")
+		return nil, nil
+	}
+	config.NewNet = func(weightsPath, configPath string) ml.NeuralNet {
+		s.Equal("data/yolov3/yolov3.weights", weightsPath)
+		s.Equal("data/yolov3/yolov3.cfg", configPath)
+		controller := gomock.NewController(s.T())
+		neuralNetMock := mocks.NewMockNeuralNet(controller)
+		neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+		neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+		return neuralNetMock
+	}
+	net, err := NewNetWithConfig("data/yolov3/yolov3.weights", "data/yolov3/yolov3.cfg", "data/yolov3/coco.names", config)
+	s.Require().NoError(err)
+	s.Equal(80, len(net.(*yoloNet).labels))
+}
+
+func (s *YoloTestSuite) TestFrameworkFromPath() {
+	tests := []struct {
+		Path      string
+		Framework string
+	}{
+		{Path: "yolov3.weights", Framework: "darknet"},
+		{Path: "yolov8n.ONNX", Framework: "onnx"},
+		{Path: "model.pb", Framework: "tensorflow"},
+		{Path: "model", Framework: ""},
+	}
+	for _, test := range tests {
+		s.Run(test.Path, func() {
+			s.Equal(test.Framework, frameworkFromPath(test.Path))
+		})
+	}
+}
+
+func (s *YoloTestSuite) readFile(path string) []byte {
+	content, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	return content
+}
+
+func ExampleNewNetFromFS() {
+	// Any fs.FS can be used, such as an embed.FS containing the models:
+	//
+	//	//go:embed data/yolov3
+	//	var models embed.FS
+	models := os.DirFS("data/yolov3")
+
+	yolonet, err := NewNetFromFS(models, "yolov3.weights", "yolov3.cfg", "coco.names", DefaultConfig())
+	if err != nil {
+		log.WithError(err).Fatal("unable to create yolo net")
+	}
+
+	// Gracefully close the net when the program is done
+	defer func() {
+		err := yolonet.Close()
+		if err != nil {
+			log.WithError(err).Error("unable to gracefully close yolo net")
+		}
+	}()
+
+	// ...
+}
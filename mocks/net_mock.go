@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	yolov3 "github.com/wimspaargaren/yolov3"
 	gocv "gocv.io/x/gocv"
@@ -49,3 +51,118 @@ func (_m *Net) GetDetections(_a0 gocv.Mat) ([]yolov3.ObjectDetection, error) {
 
 	return r0, r1
 }
+
+// GetDetectionsBatch provides a mock function with given fields: _a0
+func (_m *Net) GetDetectionsBatch(_a0 []gocv.Mat) ([][]yolov3.ObjectDetection, error) {
+	ret := _m.Called(_a0)
+
+	var r0 [][]yolov3.ObjectDetection
+	if rf, ok := ret.Get(0).(func([]gocv.Mat) [][]yolov3.ObjectDetection); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]yolov3.ObjectDetection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]gocv.Mat) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDetectionsBatchContext provides a mock function with given fields: _a0, _a1
+func (_m *Net) GetDetectionsBatchContext(_a0 context.Context, _a1 []gocv.Mat) ([][]yolov3.ObjectDetection, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 [][]yolov3.ObjectDetection
+	if rf, ok := ret.Get(0).(func(context.Context, []gocv.Mat) [][]yolov3.ObjectDetection); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]yolov3.ObjectDetection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []gocv.Mat) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDetectionsContext provides a mock function with given fields: _a0, _a1
+func (_m *Net) GetDetectionsContext(_a0 context.Context, _a1 gocv.Mat) ([]yolov3.ObjectDetection, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []yolov3.ObjectDetection
+	if rf, ok := ret.Get(0).(func(context.Context, gocv.Mat) []yolov3.ObjectDetection); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]yolov3.ObjectDetection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, gocv.Mat) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDetectionsWithFilter provides a mock function with given fields: _a0, _a1
+func (_m *Net) GetDetectionsWithFilter(_a0 gocv.Mat, _a1 yolov3.Filter) ([]yolov3.ObjectDetection, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []yolov3.ObjectDetection
+	if rf, ok := ret.Get(0).(func(gocv.Mat, yolov3.Filter) []yolov3.ObjectDetection); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]yolov3.ObjectDetection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(gocv.Mat, yolov3.Filter) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDetectionsWithFilterContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Net) GetDetectionsWithFilterContext(_a0 context.Context, _a1 gocv.Mat, _a2 yolov3.Filter) ([]yolov3.ObjectDetection, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []yolov3.ObjectDetection
+	if rf, ok := ret.Get(0).(func(context.Context, gocv.Mat, yolov3.Filter) []yolov3.ObjectDetection); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]yolov3.ObjectDetection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, gocv.Mat, yolov3.Filter) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
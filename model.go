@@ -0,0 +1,126 @@
+package yolov3
+
+import "fmt"
+
+// ModelKind describes the topology of the yolo model loaded by the net.
+type ModelKind int
+
+// Supported model kinds.
+const (
+	// ModelCustom leaves the topology of the model up to the config and the net itself.
+	ModelCustom ModelKind = iota
+	// ModelYoloV3 the full yolov3 model with three detection heads.
+	ModelYoloV3
+	// ModelYoloV3Tiny the yolov3-tiny model with two detection heads.
+	ModelYoloV3Tiny
+	// ModelYoloV4 the full yolov4 model with three detection heads.
+	ModelYoloV4
+	// ModelYoloV4Tiny the yolov4-tiny model with two detection heads.
+	ModelYoloV4Tiny
+	// ModelYoloV5 a YOLOv5 ONNX export.
+	ModelYoloV5
+	// ModelYoloV8 a YOLOv8 ONNX export.
+	ModelYoloV8
+)
+
+// modelPreset contains the default settings of a known model topology.
+type modelPreset struct {
+	inputWidth   int
+	inputHeight  int
+	outputLayers []string
+	decoder      OutputDecoder
+	framework    string
+}
+
+// String returns the name of the model kind.
+func (k ModelKind) String() string {
+	switch k {
+	case ModelCustom:
+		return "custom"
+	case ModelYoloV3:
+		return "yolov3"
+	case ModelYoloV3Tiny:
+		return "yolov3-tiny"
+	case ModelYoloV4:
+		return "yolov4"
+	case ModelYoloV4Tiny:
+		return "yolov4-tiny"
+	case ModelYoloV5:
+		return "yolov5"
+	case ModelYoloV8:
+		return "yolov8"
+	default:
+		return fmt.Sprintf("ModelKind(%d)", int(k))
+	}
+}
+
+// ParseModelKind parses the name of a model kind, as returned by ModelKind.String.
+func ParseModelKind(name string) (ModelKind, error) {
+	for k := ModelCustom; k <= ModelYoloV8; k++ {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return ModelCustom, fmt.Errorf("unknown model kind: %s", name)
+}
+
+// preset retrieves the preset of the model kind.
+func (k ModelKind) preset() (modelPreset, error) {
+	switch k {
+	case ModelCustom:
+		return modelPreset{
+			inputWidth:  DefaultInputWidth,
+			inputHeight: DefaultInputHeight,
+			decoder:     DarknetDecoder{},
+			framework:   "darknet",
+		}, nil
+	case ModelYoloV3:
+		return modelPreset{
+			inputWidth:   416,
+			inputHeight:  416,
+			outputLayers: []string{"yolo_82", "yolo_94", "yolo_106"},
+			decoder:      DarknetDecoder{},
+			framework:    "darknet",
+		}, nil
+	case ModelYoloV3Tiny:
+		return modelPreset{
+			inputWidth:   416,
+			inputHeight:  416,
+			outputLayers: []string{"yolo_16", "yolo_23"},
+			decoder:      DarknetDecoder{},
+			framework:    "darknet",
+		}, nil
+	case ModelYoloV4:
+		return modelPreset{
+			inputWidth:   608,
+			inputHeight:  608,
+			outputLayers: []string{"yolo_139", "yolo_150", "yolo_161"},
+			decoder:      DarknetDecoder{},
+			framework:    "darknet",
+		}, nil
+	case ModelYoloV4Tiny:
+		return modelPreset{
+			inputWidth:   416,
+			inputHeight:  416,
+			outputLayers: []string{"yolo_30", "yolo_37"},
+			decoder:      DarknetDecoder{},
+			framework:    "darknet",
+		}, nil
+	case ModelYoloV5:
+		return modelPreset{
+			inputWidth:  640,
+			inputHeight: 640,
+			decoder:     YoloV5Decoder{},
+			framework:   "onnx",
+		}, nil
+	case ModelYoloV8:
+		return modelPreset{
+			inputWidth:  640,
+			inputHeight: 640,
+			decoder:     YoloV8Decoder{},
+			framework:   "onnx",
+		}, nil
+	default:
+		return modelPreset{}, fmt.Errorf("unknown model kind: %s", k)
+	}
+}
@@ -0,0 +1,184 @@
+package yolov3
+
+import (
+	"image"
+
+	"github.com/golang/mock/gomock"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/internal/ml"
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+)
+
+func (s *YoloTestSuite) TestDefaultConfigForModel() {
+	tests := []struct {
+		Name                 string
+		ModelKind            ModelKind
+		ConfigPath           string
+		ExpectedInputWidth   int
+		ExpectedInputHeight  int
+		ExpectedOutputLayers []string
+	}{
+		{
+			Name:                 "yolov3",
+			ModelKind:            ModelYoloV3,
+			ConfigPath:           "data/yolov3/yolov3.cfg",
+			ExpectedInputWidth:   416,
+			ExpectedInputHeight:  416,
+			ExpectedOutputLayers: []string{"yolo_82", "yolo_94", "yolo_106"},
+		},
+		{
+			Name:                 "yolov3-tiny",
+			ModelKind:            ModelYoloV3Tiny,
+			ConfigPath:           "testdata/yolov3-tiny.cfg",
+			ExpectedInputWidth:   416,
+			ExpectedInputHeight:  416,
+			ExpectedOutputLayers: []string{"yolo_16", "yolo_23"},
+		},
+		{
+			Name:                 "yolov4",
+			ModelKind:            ModelYoloV4,
+			ConfigPath:           "testdata/yolov4.cfg",
+			ExpectedInputWidth:   608,
+			ExpectedInputHeight:  608,
+			ExpectedOutputLayers: []string{"yolo_139", "yolo_150", "yolo_161"},
+		},
+		{
+			Name:                 "yolov4-tiny",
+			ModelKind:            ModelYoloV4Tiny,
+			ConfigPath:           "testdata/yolov4-tiny.cfg",
+			ExpectedInputWidth:   416,
+			ExpectedInputHeight:  416,
+			ExpectedOutputLayers: []string{"yolo_30", "yolo_37"},
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			config := DefaultConfigForModel(test.ModelKind)
+			config.NewNet = func(string, string) ml.NeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+				return neuralNetMock
+			}
+			net, err := NewNetWithConfig("data/yolov3/yolov3.weights", test.ConfigPath, "data/yolov3/coco.names", config)
+			s.Require().NoError(err)
+			yoloNet := net.(*yoloNet)
+			s.Equal(test.Name, test.ModelKind.String())
+			s.Equal(test.ExpectedInputWidth, yoloNet.DefaultInputWidth)
+			s.Equal(test.ExpectedInputHeight, yoloNet.DefaultInputHeight)
+			s.Equal(test.ExpectedOutputLayers, yoloNet.outputLayers)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestParseModelKind() {
+	for k := ModelCustom; k <= ModelYoloV8; k++ {
+		kind, err := ParseModelKind(k.String())
+		s.Require().NoError(err)
+		s.Equal(k, kind)
+	}
+	_, err := ParseModelKind("yolov9")
+	s.EqualError(err, "unknown model kind: yolov9")
+}
+
+func (s *YoloTestSuite) TestUnknownModelKind() {
+	config := DefaultConfigForModel(ModelKind(99))
+	_, err := NewNetWithConfig("data/yolov3/yolov3.weights", "data/yolov3/yolov3.cfg", "data/yolov3/coco.names", config)
+	s.EqualError(err, "unknown model kind: ModelKind(99)")
+}
+
+func (s *YoloTestSuite) TestProcessOutputsModelLayouts() {
+	tests := []struct {
+		Name        string
+		ModelKind   ModelKind
+		RowsPerHead []int
+		Result      []ObjectDetection
+	}{
+		{
+			Name:        "yolov3 three heads",
+			ModelKind:   ModelYoloV3,
+			RowsPerHead: []int{3, 12, 48},
+			Result: []ObjectDetection{
+				{ClassID: 0, ClassName: "laptop", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(0, 0, 2, 2)},
+				{ClassID: 1, ClassName: "coffee", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(10, 10, 12, 12)},
+				{ClassID: 0, ClassName: "laptop", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(20, 20, 22, 22)},
+			},
+		},
+		{
+			Name:        "yolov3-tiny two heads",
+			ModelKind:   ModelYoloV3Tiny,
+			RowsPerHead: []int{3, 12},
+			Result: []ObjectDetection{
+				{ClassID: 0, ClassName: "laptop", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(0, 0, 2, 2)},
+				{ClassID: 1, ClassName: "coffee", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(10, 10, 12, 12)},
+			},
+		},
+		{
+			Name:        "yolov4 three heads",
+			ModelKind:   ModelYoloV4,
+			RowsPerHead: []int{48, 12, 3},
+			Result: []ObjectDetection{
+				{ClassID: 0, ClassName: "laptop", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(0, 0, 2, 2)},
+				{ClassID: 1, ClassName: "coffee", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(10, 10, 12, 12)},
+				{ClassID: 0, ClassName: "laptop", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(20, 20, 22, 22)},
+			},
+		},
+		{
+			Name:        "yolov4-tiny two heads",
+			ModelKind:   ModelYoloV4Tiny,
+			RowsPerHead: []int{12, 3},
+			Result: []ObjectDetection{
+				{ClassID: 0, ClassName: "laptop", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(0, 0, 2, 2)},
+				{ClassID: 1, ClassName: "coffee", Confidence: 9, Objectness: 1, BoundingBox: image.Rect(10, 10, 12, 12)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			preset, err := test.ModelKind.preset()
+			s.Require().NoError(err)
+			s.Require().Len(test.RowsPerHead, len(preset.outputLayers))
+
+			y := &yoloNet{
+				labels:              labelsFromNames([]string{"laptop", "coffee"}),
+				confidenceThreshold: DefaultConfThreshold,
+				DefaultNMSThreshold: DefaultNMSThreshold,
+			}
+			frame := gocv.NewMatWithSize(100, 100, gocv.MatTypeCV32F)
+			detections, err := y.processOutputs(frame, syntheticHeads(test.RowsPerHead), Filter{})
+			s.Require().NoError(err)
+			s.Equal(withBoxes(image.Pt(100, 100), test.Result), detections)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestProcessOutputsTooFewColumns() {
+	y := &yoloNet{labels: labelsFromNames([]string{"laptop", "coffee"})}
+	frame := gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F)
+	_, err := y.processOutputs(frame, []gocv.Mat{gocv.NewMatWithSize(1, 4, gocv.MatTypeCV32F)}, Filter{})
+	s.EqualError(err, "output layer 0 has 4 columns, expected at least 5")
+}
+
+// syntheticHeads creates an output Mat per detection head with given amount of rows,
+// of which the last row of each head contains a single detection. The class of the
+// detection alternates per head and each detection is placed 10 pixels further
+// on a 100x100 frame.
+func syntheticHeads(rowsPerHead []int) []gocv.Mat {
+	outputs := []gocv.Mat{}
+	for i, rows := range rowsPerHead {
+		head := gocv.NewMatWithSize(rows, 7, gocv.MatTypeCV32F)
+		center := float32(i*10+1) / 100
+		head.SetFloatAt(rows-1, 0, center)
+		head.SetFloatAt(rows-1, 1, center)
+		head.SetFloatAt(rows-1, 2, 0.02)
+		head.SetFloatAt(rows-1, 3, 0.02)
+		head.SetFloatAt(rows-1, 4, 1)
+		head.SetFloatAt(rows-1, 5+i%2, 9)
+		outputs = append(outputs, head)
+	}
+	return outputs
+}
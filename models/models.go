@@ -0,0 +1,336 @@
+// Package models provides a catalog of pre-trained yolo models and a manager able to download
+// them into a cache dir.
+//
+// Downloads are resumed when a partial download is found in the cache dir and are verified
+// using SHA-256. Files without a pinned digest are refused, unless they are marked to be trusted
+// on first use. Those have their digest recorded next to the file on first use, which is verified
+// whenever the model is used afterwards.
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CacheDirEnv is the environment variable which can be used to override the default cache dir.
+const CacheDirEnv = "YOLOV3_CACHE_DIR"
+
+const (
+	partialSuffix = ".part"
+	digestSuffix  = ".sha256"
+)
+
+// File is a single file of a model.
+type File struct {
+	// Name of the file within the directory of the model
+	Name string
+	// URL the file is downloaded from
+	URL string
+	// SHA256 hex encoded digest of the file, which is required unless the file is trusted on first use
+	SHA256 string
+	// TrustOnFirstUse records the digest of a file without a pinned digest when it is first used,
+	// either downloaded or found in the cache dir.
+	TrustOnFirstUse bool
+}
+
+// Model is a pre-trained model consisting of weights, a net config and the class names.
+type Model struct {
+	Name    string
+	Weights File
+	Config  File
+	Names   File
+}
+
+// files returns the files of the model.
+func (m Model) files() []File {
+	return []File{m.Weights, m.Config, m.Names}
+}
+
+// Paths contains the paths of the downloaded files of a model.
+type Paths struct {
+	Weights string
+	Config  string
+	Names   string
+}
+
+// DefaultCatalog returns the catalog of models known by the manager. Its files are trusted on first use,
+// as their upstream digests are not pinned.
+func DefaultCatalog() []Model {
+	names := trustOnFirstUse("coco.names", "https://raw.githubusercontent.com/pjreddie/darknet/master/data/coco.names")
+	return []Model{
+		{
+			Name:    "yolov3",
+			Weights: trustOnFirstUse("yolov3.weights", "https://pjreddie.com/media/files/yolov3.weights"),
+			Config:  trustOnFirstUse("yolov3.cfg", "https://raw.githubusercontent.com/pjreddie/darknet/master/cfg/yolov3.cfg"),
+			Names:   names,
+		},
+		{
+			Name:    "yolov3-tiny",
+			Weights: trustOnFirstUse("yolov3-tiny.weights", "https://pjreddie.com/media/files/yolov3-tiny.weights"),
+			Config:  trustOnFirstUse("yolov3-tiny.cfg", "https://raw.githubusercontent.com/pjreddie/darknet/master/cfg/yolov3-tiny.cfg"),
+			Names:   names,
+		},
+		{
+			Name: "yolov4",
+			Weights: trustOnFirstUse(
+				"yolov4.weights",
+				"https://github.com/AlexeyAB/darknet/releases/download/darknet_yolo_v3_optimal/yolov4.weights",
+			),
+			Config: trustOnFirstUse("yolov4.cfg", "https://raw.githubusercontent.com/AlexeyAB/darknet/master/cfg/yolov4.cfg"),
+			Names:  names,
+		},
+		{
+			Name: "yolov4-tiny",
+			Weights: trustOnFirstUse(
+				"yolov4-tiny.weights",
+				"https://github.com/AlexeyAB/darknet/releases/download/darknet_yolo_v4_pre/yolov4-tiny.weights",
+			),
+			Config: trustOnFirstUse("yolov4-tiny.cfg", "https://raw.githubusercontent.com/AlexeyAB/darknet/master/cfg/yolov4-tiny.cfg"),
+			Names:  names,
+		},
+	}
+}
+
+// trustOnFirstUse returns a file without a pinned digest, which is trusted on first use.
+func trustOnFirstUse(name, url string) File {
+	return File{Name: name, URL: url, TrustOnFirstUse: true}
+}
+
+// DefaultCacheDir returns the cache dir set by the YOLOV3_CACHE_DIR environment variable,
+// or a yolov3 directory in the user cache dir.
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "yolov3"), nil
+}
+
+// Manager downloads the models of its catalog into the cache dir.
+type Manager struct {
+	CacheDir string
+	Catalog  []Model
+	Client   *http.Client
+}
+
+// NewManager creates a manager for the default catalog. When the cache dir is empty the default cache dir is used.
+func NewManager(cacheDir string) (*Manager, error) {
+	if cacheDir == "" {
+		var err error
+		cacheDir, err = DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Manager{
+		CacheDir: cacheDir,
+		Catalog:  DefaultCatalog(),
+		Client:   http.DefaultClient,
+	}, nil
+}
+
+// Model retrieves the model with given name from the catalog.
+func (m *Manager) Model(name string) (Model, error) {
+	for _, model := range m.Catalog {
+		if model.Name == name {
+			return model, nil
+		}
+	}
+	return Model{}, fmt.Errorf("unknown model: %s", name)
+}
+
+// Paths returns the paths of the files of given model in the cache dir, regardless of whether they have been downloaded.
+func (m *Manager) Paths(name string) (Paths, error) {
+	model, err := m.Model(name)
+	if err != nil {
+		return Paths{}, err
+	}
+	return Paths{
+		Weights: m.path(model, model.Weights),
+		Config:  m.path(model, model.Config),
+		Names:   m.path(model, model.Names),
+	}, nil
+}
+
+// Ensure downloads the files of given model which are not present in the cache dir yet
+// and verifies the digests of all its files.
+func (m *Manager) Ensure(ctx context.Context, name string) (Paths, error) {
+	model, err := m.Model(name)
+	if err != nil {
+		return Paths{}, err
+	}
+	err = os.MkdirAll(filepath.Join(m.CacheDir, model.Name), 0o755)
+	if err != nil {
+		return Paths{}, err
+	}
+	for _, file := range model.files() {
+		err = m.ensureFile(ctx, file, m.path(model, file))
+		if err != nil {
+			return Paths{}, fmt.Errorf("unable to retrieve %s of model %s: %w", file.Name, model.Name, err)
+		}
+	}
+	return m.Paths(name)
+}
+
+// path returns the path of a file of the model in the cache dir.
+func (m *Manager) path(model Model, file File) string {
+	return filepath.Join(m.CacheDir, model.Name, file.Name)
+}
+
+// ensureFile downloads the file to given path if not present yet and verifies its digest.
+func (m *Manager) ensureFile(ctx context.Context, file File, path string) error {
+	expected, err := expectedDigest(file, path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if expected == "" {
+			return recordDigest(path, path)
+		}
+		return verifyDigest(path, expected)
+	}
+
+	err = m.download(ctx, file.URL, path+partialSuffix)
+	if err != nil {
+		return err
+	}
+	err = verifyDigest(path+partialSuffix, expected)
+	if err != nil {
+		// Remove the download, as resuming a corrupt download is pointless
+		return errors.Join(err, os.Remove(path+partialSuffix))
+	}
+	if expected == "" {
+		err = recordDigest(path+partialSuffix, path)
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(path+partialSuffix, path)
+}
+
+// recordDigest records the digest of the file at given path next to the file of the model at target.
+func recordDigest(path, target string) error {
+	digest, err := fileDigest(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(target+digestSuffix, []byte(digest+"\n"), 0o600)
+}
+
+// download downloads the given url to path, resuming a previous partial download if present.
+func (m *Manager) download(ctx context.Context, url, path string) error {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server does not support resuming, start over
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial download is at least as large as the file, start over
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+		return m.download(ctx, url, path)
+	default:
+		return fmt.Errorf("unexpected status downloading %s: %s", url, resp.Status)
+	}
+
+	f, err := os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, resp.Body)
+	if err != nil {
+		return errors.Join(err, f.Close())
+	}
+	return f.Close()
+}
+
+// expectedDigest retrieves the pinned digest of the file, or the digest recorded at the first download
+// for files which are trusted on first use.
+func expectedDigest(file File, path string) (string, error) {
+	if file.SHA256 != "" {
+		return strings.ToLower(file.SHA256), nil
+	}
+	if !file.TrustOnFirstUse {
+		return "", fmt.Errorf("no SHA-256 digest pinned for %s", file.Name)
+	}
+	content, err := os.ReadFile(path + digestSuffix)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// verifyDigest verifies the SHA-256 digest of the file at given path, an empty digest is not verified.
+func verifyDigest(path, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	digest, err := fileDigest(path)
+	if err != nil {
+		return err
+	}
+	if digest != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filepath.Base(path), expected, digest)
+	}
+	return nil
+}
+
+// fileDigest calculates the hex encoded SHA-256 digest of the file at given path.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,283 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ManagerTestSuite struct {
+	suite.Suite
+
+	server   *httptest.Server
+	files    map[string][]byte
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func TestManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(ManagerTestSuite))
+}
+
+func (s *ManagerTestSuite) SetupTest() {
+	s.files = map[string][]byte{
+		"/tiny.weights": []byte(strings.Repeat("weights", 100)),
+		"/tiny.cfg":     []byte("[net]\nwidth=416\n"),
+		"/coco.names":   []byte("laptop\ncoffee\n"),
+	}
+	s.requests = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		s.mu.Unlock()
+		content, ok := s.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, strings.NewReader(string(content)))
+	}))
+}
+
+func (s *ManagerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ManagerTestSuite) manager(pinned bool) *Manager {
+	file := func(name, path string) File {
+		f := File{Name: name, URL: s.server.URL + path}
+		if pinned {
+			f.SHA256 = digest(s.files[path])
+		} else {
+			f.TrustOnFirstUse = true
+		}
+		return f
+	}
+	return &Manager{
+		CacheDir: s.T().TempDir(),
+		Catalog: []Model{
+			{
+				Name:    "tiny",
+				Weights: file("tiny.weights", "/tiny.weights"),
+				Config:  file("tiny.cfg", "/tiny.cfg"),
+				Names:   file("coco.names", "/coco.names"),
+			},
+		},
+		Client: s.server.Client(),
+	}
+}
+
+func (s *ManagerTestSuite) TestEnsureDownloadsModel() {
+	for _, pinned := range []bool{true, false} {
+		manager := s.manager(pinned)
+		paths, err := manager.Ensure(context.Background(), "tiny")
+		s.Require().NoError(err)
+
+		s.Equal(filepath.Join(manager.CacheDir, "tiny", "tiny.weights"), paths.Weights)
+		s.fileEquals(paths.Weights, s.files["/tiny.weights"])
+		s.fileEquals(paths.Config, s.files["/tiny.cfg"])
+		s.fileEquals(paths.Names, s.files["/coco.names"])
+		s.NoFileExists(paths.Weights + partialSuffix)
+		if pinned {
+			s.NoFileExists(paths.Weights + digestSuffix)
+		} else {
+			s.fileEquals(paths.Weights+digestSuffix, []byte(digest(s.files["/tiny.weights"])+"\n"))
+		}
+	}
+}
+
+func (s *ManagerTestSuite) TestEnsureUsesCache() {
+	manager := s.manager(true)
+	_, err := manager.Ensure(context.Background(), "tiny")
+	s.Require().NoError(err)
+	s.Len(s.requests, 3)
+
+	_, err = manager.Ensure(context.Background(), "tiny")
+	s.Require().NoError(err)
+	s.Len(s.requests, 3)
+}
+
+func (s *ManagerTestSuite) TestEnsureResumesPartialDownload() {
+	manager := s.manager(true)
+	paths, err := manager.Paths("tiny")
+	s.Require().NoError(err)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(paths.Weights), 0o755))
+	s.Require().NoError(os.WriteFile(paths.Weights+partialSuffix, s.files["/tiny.weights"][:100], 0o600))
+
+	_, err = manager.Ensure(context.Background(), "tiny")
+	s.Require().NoError(err)
+	s.fileEquals(paths.Weights, s.files["/tiny.weights"])
+	s.Equal("bytes=100-", s.requests[0].Header.Get("Range"))
+}
+
+func (s *ManagerTestSuite) TestEnsureRestartsOversizedPartialDownload() {
+	manager := s.manager(true)
+	paths, err := manager.Paths("tiny")
+	s.Require().NoError(err)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(paths.Weights), 0o755))
+	oversized := append(append([]byte{}, s.files["/tiny.weights"]...), []byte("garbage")...)
+	s.Require().NoError(os.WriteFile(paths.Weights+partialSuffix, oversized, 0o600))
+
+	_, err = manager.Ensure(context.Background(), "tiny")
+	s.Require().NoError(err)
+	s.fileEquals(paths.Weights, s.files["/tiny.weights"])
+}
+
+func (s *ManagerTestSuite) TestEnsureChecksumMismatch() {
+	manager := s.manager(true)
+	manager.Catalog[0].Weights.SHA256 = digest([]byte("other"))
+
+	_, err := manager.Ensure(context.Background(), "tiny")
+	s.Require().Error(err)
+	s.Contains(err.Error(), "checksum mismatch for tiny.weights.part")
+
+	paths, err := manager.Paths("tiny")
+	s.Require().NoError(err)
+	s.NoFileExists(paths.Weights)
+	s.NoFileExists(paths.Weights + partialSuffix)
+}
+
+func (s *ManagerTestSuite) TestEnsureRequiresDigest() {
+	manager := s.manager(true)
+	manager.Catalog[0].Config.SHA256 = ""
+
+	_, err := manager.Ensure(context.Background(), "tiny")
+	s.EqualError(err, "unable to retrieve tiny.cfg of model tiny: no SHA-256 digest pinned for tiny.cfg")
+
+	paths, err := manager.Paths("tiny")
+	s.Require().NoError(err)
+	s.NoFileExists(paths.Config)
+	s.NoFileExists(paths.Config + digestSuffix)
+}
+
+func (s *ManagerTestSuite) TestEnsureDetectsCorruptedCache() {
+	manager := s.manager(false)
+	paths, err := manager.Ensure(context.Background(), "tiny")
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(paths.Weights, []byte("corrupted"), 0o600))
+
+	_, err = manager.Ensure(context.Background(), "tiny")
+	s.Require().Error(err)
+	s.Contains(err.Error(), "checksum mismatch for tiny.weights")
+}
+
+func (s *ManagerTestSuite) TestEnsureTrustsCachedFiles() {
+	manager := s.manager(false)
+	paths, err := manager.Paths("tiny")
+	s.Require().NoError(err)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(paths.Weights), 0o755))
+	for path, content := range map[string][]byte{
+		paths.Weights: s.files["/tiny.weights"],
+		paths.Config:  s.files["/tiny.cfg"],
+		paths.Names:   s.files["/coco.names"],
+	} {
+		s.Require().NoError(os.WriteFile(path, content, 0o600))
+	}
+
+	_, err = manager.Ensure(context.Background(), "tiny")
+	s.Require().NoError(err)
+	s.Empty(s.requests)
+	s.fileEquals(paths.Weights+digestSuffix, []byte(digest(s.files["/tiny.weights"])+"\n"))
+
+	s.Require().NoError(os.WriteFile(paths.Weights, []byte("corrupted"), 0o600))
+	_, err = manager.Ensure(context.Background(), "tiny")
+	s.Require().Error(err)
+	s.Contains(err.Error(), "checksum mismatch for tiny.weights")
+}
+
+func (s *ManagerTestSuite) TestEnsureErrors() {
+	tests := []struct {
+		Name   string
+		Model  string
+		Setup  func(m *Manager)
+		Ctx    func() context.Context
+		Errors string
+	}{
+		{
+			Name:   "Unknown model",
+			Model:  "notexistent",
+			Errors: "unknown model: notexistent",
+		},
+		{
+			Name:  "File not found on server",
+			Model: "tiny",
+			Setup: func(m *Manager) {
+				m.Catalog[0].Config.URL = s.server.URL + "/notexistent"
+			},
+			Errors: "unable to retrieve tiny.cfg of model tiny: unexpected status downloading",
+		},
+		{
+			Name:  "Cancelled context",
+			Model: "tiny",
+			Ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			Errors: "context canceled",
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			manager := s.manager(true)
+			if test.Setup != nil {
+				test.Setup(manager)
+			}
+			ctx := context.Background()
+			if test.Ctx != nil {
+				ctx = test.Ctx()
+			}
+			_, err := manager.Ensure(ctx, test.Model)
+			s.Require().Error(err)
+			s.Contains(err.Error(), test.Errors)
+		})
+	}
+}
+
+func (s *ManagerTestSuite) TestDefaultCacheDir() {
+	s.T().Setenv(CacheDirEnv, "/tmp/yolov3-models")
+	dir, err := DefaultCacheDir()
+	s.Require().NoError(err)
+	s.Equal("/tmp/yolov3-models", dir)
+
+	manager, err := NewManager("")
+	s.Require().NoError(err)
+	s.Equal("/tmp/yolov3-models", manager.CacheDir)
+}
+
+func (s *ManagerTestSuite) TestDefaultCatalog() {
+	manager, err := NewManager(s.T().TempDir())
+	s.Require().NoError(err)
+	for _, name := range []string{"yolov3", "yolov3-tiny", "yolov4", "yolov4-tiny"} {
+		model, err := manager.Model(name)
+		s.Require().NoError(err)
+		s.Equal(name+".weights", model.Weights.Name)
+		s.Equal(name+".cfg", model.Config.Name)
+		s.Equal("coco.names", model.Names.Name)
+		for _, file := range model.files() {
+			s.True(file.SHA256 != "" || file.TrustOnFirstUse, file.Name)
+		}
+	}
+}
+
+func (s *ManagerTestSuite) fileEquals(path string, expected []byte) {
+	content, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(expected, content)
+}
+
+func digest(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,85 @@
+package yolov3
+
+import (
+	"github.com/wimspaargaren/yolov3/internal/nms"
+)
+
+// DefaultSoftNMSSigma is the default sigma of the Gaussian decay of Soft-NMS.
+const DefaultSoftNMSSigma float32 = 0.5
+
+// NMSStrategy determines how overlapping boxes are suppressed by non-maximum suppression.
+// Before NMS strategies were introduced, overlapping boxes were suppressed regardless of their class.
+// The default is now NMSPerClass, use NMSClassAgnostic to keep the previous behaviour.
+type NMSStrategy int
+
+const (
+	// NMSPerClass suppresses overlapping boxes of the same class, such that objects of different
+	// classes in front of each other are all detected
+	NMSPerClass NMSStrategy = iota
+	// NMSClassAgnostic suppresses overlapping boxes regardless of their class
+	NMSClassAgnostic
+	// NMSSoftLinear decays the confidence of boxes overlapping a more confident box of the same class
+	// linearly with their overlap, instead of suppressing them
+	NMSSoftLinear
+	// NMSSoftGaussian decays the confidence of boxes overlapping a more confident box of the same class
+	// using a Gaussian of their overlap, instead of suppressing them
+	NMSSoftGaussian
+	// NMSDIoU suppresses overlapping boxes of the same class based on their overlap minus the distance
+	// between their centers, such that nearby objects are less likely to be suppressed
+	NMSDIoU
+)
+
+// nonMaxSuppression applies the non-maximum suppression strategy of the net to the detections.
+// The remaining detections are sorted by confidence, detections with equal confidence keep their order.
+func (y *yoloNet) nonMaxSuppression(detections []ObjectDetection) []ObjectDetection {
+	boxes, scores, classIDs := nmsInput(detections)
+	indices := []int{}
+	for i := range detections {
+		indices = append(indices, i)
+	}
+
+	var kept []int
+	switch y.nmsStrategy {
+	case NMSClassAgnostic:
+		kept = nms.Greedy(boxes, scores, indices, y.minConfidenceThreshold(), y.DefaultNMSThreshold, nms.IoU)
+	case NMSSoftLinear, NMSSoftGaussian:
+		sigma := y.softNMSSigma
+		if sigma <= 0 {
+			sigma = DefaultSoftNMSSigma
+		}
+		kept = nms.PerClass(classIDs, func(classID int, indices []int) []int {
+			return nms.Soft(boxes, scores, indices, y.DefaultNMSThreshold, sigma, y.nmsStrategy == NMSSoftGaussian, y.classConfidenceThreshold(classID))
+		})
+	case NMSDIoU:
+		kept = nms.PerClass(classIDs, func(classID int, indices []int) []int {
+			return nms.Greedy(boxes, scores, indices, y.classConfidenceThreshold(classID), y.DefaultNMSThreshold, nms.DIoU)
+		})
+	default:
+		kept = nms.PerClass(classIDs, func(classID int, indices []int) []int {
+			return nms.Greedy(boxes, scores, indices, y.classConfidenceThreshold(classID), y.DefaultNMSThreshold, nms.IoU)
+		})
+	}
+
+	nms.SortByScore(scores, kept)
+	result := []ObjectDetection{}
+	for _, i := range kept {
+		// Soft-NMS decays the confidences
+		detection := detections[i]
+		detection.Confidence = scores[i]
+		result = append(result, detection)
+	}
+	return result
+}
+
+// nmsInput retrieves the boxes, confidences and class ids of the detections for non-maximum suppression.
+func nmsInput(detections []ObjectDetection) ([]nms.Box, []float32, []int) {
+	boxes := []nms.Box{}
+	scores := []float32{}
+	classIDs := []int{}
+	for _, detection := range detections {
+		boxes = append(boxes, nms.Box(detection.box()))
+		scores = append(scores, detection.Confidence)
+		classIDs = append(classIDs, detection.ClassID)
+	}
+	return boxes, scores, classIDs
+}
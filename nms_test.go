@@ -0,0 +1,188 @@
+package yolov3
+
+import (
+	"image"
+)
+
+func (s *YoloTestSuite) TestNonMaxSuppression() {
+	person := func(confidence float32, box image.Rectangle) ObjectDetection {
+		return ObjectDetection{ClassID: 0, ClassName: "person", Confidence: confidence, BoundingBox: box}
+	}
+	car := func(confidence float32, box image.Rectangle) ObjectDetection {
+		return ObjectDetection{ClassID: 2, ClassName: "car", Confidence: confidence, BoundingBox: box}
+	}
+	subPixel := func(confidence float32, box Box) ObjectDetection {
+		detection := ObjectDetection{ClassID: 0, ClassName: "person", Confidence: confidence}
+		detection.setBox(box, image.Pt(100, 100))
+		return detection
+	}
+	tests := []struct {
+		Name       string
+		Strategy   NMSStrategy
+		Detections []ObjectDetection
+		Result     []ObjectDetection
+	}{
+		{
+			Name:     "Per class keeps a car behind a person",
+			Strategy: NMSPerClass,
+			Detections: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				car(0.8, image.Rect(20, 20, 120, 200)),
+				person(0.7, image.Rect(10, 0, 110, 200)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				car(0.8, image.Rect(20, 20, 120, 200)),
+			},
+		},
+		{
+			Name:     "Class agnostic suppresses a car behind a person",
+			Strategy: NMSClassAgnostic,
+			Detections: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				car(0.8, image.Rect(20, 20, 120, 200)),
+				person(0.7, image.Rect(10, 0, 110, 200)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+			},
+		},
+		{
+			Name:     "Sorted by confidence",
+			Strategy: NMSPerClass,
+			Detections: []ObjectDetection{
+				// The least confident detection comes first
+				person(0.2, image.Rect(500, 0, 600, 200)),
+				car(0.8, image.Rect(20, 20, 120, 200)),
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				car(0.8, image.Rect(300, 20, 400, 200)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				car(0.8, image.Rect(20, 20, 120, 200)),
+				car(0.8, image.Rect(300, 20, 400, 200)),
+				person(0.2, image.Rect(500, 0, 600, 200)),
+			},
+		},
+		{
+			Name:     "Below the confidence threshold",
+			Strategy: NMSClassAgnostic,
+			Detections: []ObjectDetection{
+				person(0.05, image.Rect(500, 0, 600, 200)),
+				car(0.8, image.Rect(20, 20, 120, 200)),
+			},
+			Result: []ObjectDetection{
+				car(0.8, image.Rect(20, 20, 120, 200)),
+			},
+		},
+		{
+			Name:     "Soft linear decays the confidence of overlapping boxes",
+			Strategy: NMSSoftLinear,
+			Detections: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				// Intersection over union of 0.6
+				person(0.8, image.Rect(25, 0, 125, 200)),
+				// Intersection over union of 0.25, below the threshold
+				person(0.7, image.Rect(60, 0, 160, 200)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				person(0.7, image.Rect(60, 0, 160, 200)),
+				person(0.8*0.4, image.Rect(25, 0, 125, 200)),
+			},
+		},
+		{
+			Name:     "Soft gaussian decays the confidence of all overlapping boxes",
+			Strategy: NMSSoftGaussian,
+			Detections: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				person(0.8, image.Rect(25, 0, 125, 200)),
+				// Distinct box, which is not decayed
+				person(0.2, image.Rect(500, 0, 600, 200)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				// exp(-0.6 * 0.6 / 0.5)
+				person(0.8*0.48675226, image.Rect(25, 0, 125, 200)),
+				person(0.2, image.Rect(500, 0, 600, 200)),
+			},
+		},
+		{
+			Name:     "Soft linear drops boxes decayed below the threshold",
+			Strategy: NMSSoftLinear,
+			Detections: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+				// Intersection over union of 0.9
+				person(0.8, image.Rect(0, 0, 100, 180)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 200)),
+			},
+		},
+		{
+			Name:     "Regular suppression of boxes next to each other",
+			Strategy: NMSPerClass,
+			Detections: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 20)),
+				// Intersection over union of 0.54
+				person(0.8, image.Rect(30, 0, 130, 20)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 20)),
+			},
+		},
+		{
+			Name:     "Sub-pixel boxes are suppressed",
+			Strategy: NMSPerClass,
+			Detections: []ObjectDetection{
+				subPixel(0.9, Box{MinX: 0, MinY: 0, MaxX: 30.4, MaxY: 10}),
+				// Intersection over union of 0.52, while the bounding boxes have an intersection over union of 0.5
+				subPixel(0.8, Box{MinX: 9.6, MinY: 0, MaxX: 40, MaxY: 10}),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 30, 10)),
+			},
+		},
+		{
+			Name:     "DIoU keeps boxes next to each other",
+			Strategy: NMSDIoU,
+			Detections: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 20)),
+				person(0.8, image.Rect(30, 0, 130, 20)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 20)),
+				person(0.8, image.Rect(30, 0, 130, 20)),
+			},
+		},
+		{
+			Name:     "DIoU suppresses boxes with the same center",
+			Strategy: NMSDIoU,
+			Detections: []ObjectDetection{
+				person(0.8, image.Rect(10, 10, 90, 90)),
+				person(0.9, image.Rect(0, 0, 100, 100)),
+				car(0.8, image.Rect(10, 10, 90, 90)),
+			},
+			Result: []ObjectDetection{
+				person(0.9, image.Rect(0, 0, 100, 100)),
+				car(0.8, image.Rect(10, 10, 90, 90)),
+			},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			y := &yoloNet{
+				confidenceThreshold: 0.1,
+				DefaultNMSThreshold: 0.5,
+				nmsStrategy:         test.Strategy,
+			}
+			result := y.nonMaxSuppression(test.Detections)
+			s.Require().Len(result, len(test.Result))
+			for i := range result {
+				s.Equal(test.Result[i].ClassID, result[i].ClassID)
+				s.Equal(test.Result[i].BoundingBox, result[i].BoundingBox)
+				s.InDelta(test.Result[i].Confidence, result[i].Confidence, 1e-6)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package yolov3
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// ErrNetClosed is returned when detecting objects using a net which has been closed.
+var ErrNetClosed = errors.New("net is closed")
+
+// ErrNetPoolFull is returned when all nets of a pool are in use and the queue of waiting callers is full.
+var ErrNetPoolFull = errors.New("net pool queue is full")
+
+// NetPoolConfig can be used to customise the size of a NetPool.
+type NetPoolConfig struct {
+	// Size is the amount of nets in the pool, defaults to one
+	Size int
+	// MaxQueue is the maximum amount of callers waiting for a net, once reached calls fail with
+	// ErrNetPoolFull. When left empty the amount of waiting callers is not limited.
+	MaxQueue int
+}
+
+// NetPool is a Net which holds independently initialised nets and hands them out to
+// concurrent callers, such that every net is used by a single goroutine at a time.
+type NetPool struct {
+	nets  []Net
+	idle  chan Net
+	queue chan struct{}
+
+	// mu guards the closed state
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{}
+	active  sync.WaitGroup
+}
+
+// NewNetPool creates a pool of yolo nets with given config, each net is created using NewNetWithConfig.
+func NewNetPool(weightsPath, configPath, cocoNamePath string, config Config, poolConfig NetPoolConfig) (*NetPool, error) {
+	return NewNetPoolFromFunc(func() (Net, error) {
+		return NewNetWithConfig(weightsPath, configPath, cocoNamePath, config)
+	}, poolConfig)
+}
+
+// NewNetPoolFromFunc creates a pool of nets created by the given function.
+func NewNetPoolFromFunc(newNet func() (Net, error), poolConfig NetPoolConfig) (*NetPool, error) {
+	size := poolConfig.Size
+	if size <= 0 {
+		size = 1
+	}
+	pool := &NetPool{
+		idle:    make(chan Net, size),
+		closing: make(chan struct{}),
+	}
+	if poolConfig.MaxQueue > 0 {
+		pool.queue = make(chan struct{}, poolConfig.MaxQueue)
+	}
+	for i := 0; i < size; i++ {
+		net, err := newNet()
+		if err != nil {
+			return nil, errors.Join(err, pool.closeNets())
+		}
+		pool.nets = append(pool.nets, net)
+		pool.idle <- net
+	}
+	return pool, nil
+}
+
+// acquire waits for an idle net, which should be released once the call is done.
+func (p *NetPool) acquire(ctx context.Context) (Net, error) {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return nil, ErrNetClosed
+	}
+	p.active.Add(1)
+	p.mu.RUnlock()
+
+	select {
+	case net := <-p.idle:
+		return net, nil
+	default:
+	}
+
+	if p.queue != nil {
+		select {
+		case p.queue <- struct{}{}:
+			defer func() { <-p.queue }()
+		default:
+			p.active.Done()
+			return nil, ErrNetPoolFull
+		}
+	}
+
+	select {
+	case net := <-p.idle:
+		return net, nil
+	case <-ctx.Done():
+		p.active.Done()
+		return nil, ctx.Err()
+	case <-p.closing:
+		p.active.Done()
+		return nil, ErrNetClosed
+	}
+}
+
+// release hands the net back to the pool.
+func (p *NetPool) release(net Net) {
+	p.idle <- net
+	p.active.Done()
+}
+
+// Close stops handing out nets, waits for running calls to finish and closes all nets of the pool.
+// Callers waiting for a net fail with ErrNetClosed.
+func (p *NetPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.closing)
+	p.mu.Unlock()
+
+	p.active.Wait()
+	return p.closeNets()
+}
+
+// closeNets closes all nets of the pool.
+func (p *NetPool) closeNets() error {
+	var err error
+	for _, net := range p.nets {
+		err = errors.Join(err, net.Close())
+	}
+	return err
+}
+
+// GetDetections retrieve predicted detections from given matrix using a net of the pool.
+func (p *NetPool) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	return p.GetDetectionsContext(context.Background(), frame)
+}
+
+// GetDetectionsWithFilter allows you to detect objects using a net of the pool, reporting the classes allowed by given filter.
+func (p *NetPool) GetDetectionsWithFilter(frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	return p.GetDetectionsWithFilterContext(context.Background(), frame, filter)
+}
+
+// GetDetectionsContext retrieve predicted detections from given matrix using a net of the pool,
+// unless the context is done while waiting for a net or detecting objects.
+func (p *NetPool) GetDetectionsContext(ctx context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+	net, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(net)
+	return net.GetDetectionsContext(ctx, frame)
+}
+
+// GetDetectionsWithFilterContext allows you to detect objects using a net of the pool, reporting the classes allowed by given filter.
+func (p *NetPool) GetDetectionsWithFilterContext(ctx context.Context, frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	net, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(net)
+	return net.GetDetectionsWithFilterContext(ctx, frame, filter)
+}
+
+// GetDetectionsBatch retrieve predicted detections from given matrices in a single forward pass using a net of the pool.
+func (p *NetPool) GetDetectionsBatch(frames []gocv.Mat) ([][]ObjectDetection, error) {
+	return p.GetDetectionsBatchContext(context.Background(), frames)
+}
+
+// GetDetectionsBatchContext retrieve predicted detections from given matrices in a single forward pass using
+// a net of the pool, unless the context is done while waiting for a net or detecting objects.
+func (p *NetPool) GetDetectionsBatchContext(ctx context.Context, frames []gocv.Mat) ([][]ObjectDetection, error) {
+	net, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(net)
+	return net.GetDetectionsBatchContext(ctx, frames)
+}
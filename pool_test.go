@@ -0,0 +1,204 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/darknet"
+	"github.com/wimspaargaren/yolov3/internal/ml"
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+)
+
+// poolNeuralNet creates a neural net mock for a pool, forward calls are passed to given function
+// and the net fails the test when it is used by multiple goroutines at once.
+func (s *YoloTestSuite) poolNeuralNet(forward func() []gocv.Mat, closed *atomic.Int32) ml.NeuralNet {
+	var inUse atomic.Bool
+	controller := gomock.NewController(s.T())
+	neuralNetMock := mocks.NewMockNeuralNet(controller)
+	neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+	neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+	neuralNetMock.EXPECT().SetInput(gomock.Any(), gomock.Any()).Do(func(gocv.Mat, string) {
+		if !inUse.CompareAndSwap(false, true) {
+			s.Fail("net is used concurrently")
+		}
+	}).AnyTimes()
+	neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).DoAndReturn(func([]string) []gocv.Mat {
+		defer inUse.Store(false)
+		return forward()
+	}).AnyTimes()
+	neuralNetMock.EXPECT().Close().DoAndReturn(func() error {
+		closed.Add(1)
+		return nil
+	}).Times(1)
+	return neuralNetMock
+}
+
+// newTestNetPool creates a pool of yolov3 nets backed by neural net mocks.
+func (s *YoloTestSuite) newTestNetPool(poolConfig NetPoolConfig, forward func() []gocv.Mat, created, closed *atomic.Int32) *NetPool {
+	config := DefaultConfig()
+	config.NewNet = func(string, string) ml.NeuralNet {
+		created.Add(1)
+		return s.poolNeuralNet(forward, closed)
+	}
+	pool, err := NewNetPool("data/yolov3/yolov3.weights", "data/yolov3/yolov3.cfg", "data/yolov3/coco.names", config, poolConfig)
+	s.Require().NoError(err)
+	return pool
+}
+
+func (s *YoloTestSuite) TestNetPoolConcurrentDetections() {
+	var created, closed atomic.Int32
+	pool := s.newTestNetPool(NetPoolConfig{Size: 4}, func() []gocv.Mat {
+		return []gocv.Mat{laptopDetection()}
+	}, &created, &closed)
+	s.Equal(int32(4), created.Load())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			detections, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+			s.NoError(err)
+			s.Len(detections, 1)
+		}()
+	}
+	wg.Wait()
+
+	s.NoError(pool.Close())
+	s.Equal(int32(4), closed.Load())
+	s.NoError(pool.Close())
+
+	_, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+	s.ErrorIs(err, ErrNetClosed)
+}
+
+func (s *YoloTestSuite) TestNetPoolQueue() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var forwards atomic.Int32
+	var created, closed atomic.Int32
+	pool := s.newTestNetPool(NetPoolConfig{Size: 1, MaxQueue: 1}, func() []gocv.Mat {
+		if forwards.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return []gocv.Mat{laptopDetection()}
+	}, &created, &closed)
+
+	// The first call occupies the only net of the pool
+	results := make(chan error, 2)
+	go func() {
+		_, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+		results <- err
+	}()
+	<-started
+
+	// The second call waits in the queue
+	go func() {
+		_, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+		results <- err
+	}()
+	s.Eventually(func() bool { return len(pool.queue) == 1 }, time.Second, time.Millisecond)
+
+	// The third call finds the queue full
+	_, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+	s.ErrorIs(err, ErrNetPoolFull)
+
+	close(release)
+	s.NoError(<-results)
+	s.NoError(<-results)
+	s.NoError(pool.Close())
+}
+
+func (s *YoloTestSuite) TestNetPoolCancelWhileWaiting() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var forwards atomic.Int32
+	var created, closed atomic.Int32
+	pool := s.newTestNetPool(NetPoolConfig{Size: 1}, func() []gocv.Mat {
+		if forwards.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return []gocv.Mat{laptopDetection()}
+	}, &created, &closed)
+
+	result := make(chan error)
+	go func() {
+		_, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+		result <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := pool.GetDetectionsContext(ctx, gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+	s.ErrorIs(err, context.DeadlineExceeded)
+
+	close(release)
+	s.NoError(<-result)
+	s.NoError(pool.Close())
+}
+
+func (s *YoloTestSuite) TestNetPoolCloseWaitsForRunningCalls() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var forwards atomic.Int32
+	var created, closed atomic.Int32
+	pool := s.newTestNetPool(NetPoolConfig{Size: 1}, func() []gocv.Mat {
+		if forwards.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return []gocv.Mat{laptopDetection()}
+	}, &created, &closed)
+
+	running := make(chan error)
+	go func() {
+		_, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+		running <- err
+	}()
+	<-started
+
+	waiting := make(chan error)
+	go func() {
+		_, err := pool.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+		waiting <- err
+	}()
+
+	closeResult := make(chan error)
+	go func() {
+		closeResult <- pool.Close()
+	}()
+
+	// Callers waiting for a net are rejected, the running call finishes before the nets are closed
+	s.ErrorIs(<-waiting, ErrNetClosed)
+	s.Equal(int32(0), closed.Load())
+	close(release)
+	s.NoError(<-running)
+	s.NoError(<-closeResult)
+	s.Equal(int32(1), closed.Load())
+}
+
+func (s *YoloTestSuite) TestNewNetPoolFailure() {
+	var closed atomic.Int32
+	nets := 0
+	_, err := NewNetPoolFromFunc(func() (Net, error) {
+		nets++
+		if nets == 3 {
+			return nil, fmt.Errorf("very broken")
+		}
+		config := DefaultConfig()
+		config.OutputLayers = []string{"yolo_82"}
+		s.Require().NoError(config.validate())
+		return newYoloNet(s.poolNeuralNet(nil, &closed), labelsFromNames([]string{"laptop", "coffee"}), &darknet.Config{YoloLayers: []darknet.YoloLayer{{Classes: 2}}}, config)
+	}, NetPoolConfig{Size: 3})
+	s.EqualError(err, "very broken")
+	s.Equal(int32(2), closed.Load())
+}
@@ -0,0 +1,332 @@
+package yolov3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+
+	"gocv.io/x/gocv"
+)
+
+// DefaultRegionMinOverlap is the default minimum overlap of a box with the polygons in RegionMatchOverlap mode.
+const DefaultRegionMinOverlap float32 = 0.5
+
+// Polygon is a closed polygon in frame coordinates.
+type Polygon []image.Point
+
+// RegionMatch determines how detections are matched against the polygons of the regions.
+type RegionMatch int
+
+const (
+	// RegionMatchCenter matches detections of which the center of the box lies within a polygon
+	RegionMatchCenter RegionMatch = iota
+	// RegionMatchOverlap matches detections of which the part of the box covered by the polygons
+	// is at least the minimum overlap
+	RegionMatchOverlap
+)
+
+// Regions determine the parts of the frame in which detections are reported.
+type Regions struct {
+	// Include polygons, when set detections are only reported when they match one of them
+	Include []Polygon
+	// Exclude polygons, detections matching one of them are dropped
+	Exclude []Polygon
+	// Crop crops the frame to the bounding rectangle of the include polygons before detecting objects
+	Crop bool
+	// Match determines how detections are matched against the polygons, defaults to the center of the box
+	Match RegionMatch
+	// MinOverlap is the part of the box which needs to be covered by the polygons in RegionMatchOverlap mode,
+	// defaults to DefaultRegionMinOverlap. The polygons are assumed not to overlap each other.
+	MinOverlap float32
+}
+
+// regionsEntry are the regions as written in a JSON region file.
+type regionsEntry struct {
+	Include    [][][2]int `json:"include"`
+	Exclude    [][][2]int `json:"exclude"`
+	Crop       bool       `json:"crop"`
+	Match      string     `json:"match"`
+	MinOverlap float32    `json:"min_overlap"`
+}
+
+// LoadRegions loads the regions from given JSON file. Polygons are lists of x, y coordinates, for example:
+//
+//	{
+//		"include": [[[0, 200], [640, 200], [640, 480], [0, 480]]],
+//		"exclude": [[[500, 200], [640, 200], [640, 300]]],
+//		"crop": true,
+//		"match": "overlap",
+//		"min_overlap": 0.3
+//	}
+func LoadRegions(path string) (Regions, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Regions{}, err
+	}
+	return parseRegions(content)
+}
+
+// parseRegions parses the content of a JSON region file.
+func parseRegions(content []byte) (Regions, error) {
+	entry := regionsEntry{}
+	err := json.Unmarshal(content, &entry)
+	if err != nil {
+		return Regions{}, fmt.Errorf("unable to parse regions: %w", err)
+	}
+
+	regions := Regions{Crop: entry.Crop, MinOverlap: entry.MinOverlap}
+	switch entry.Match {
+	case "", "center":
+		regions.Match = RegionMatchCenter
+	case "overlap":
+		regions.Match = RegionMatchOverlap
+	default:
+		return Regions{}, fmt.Errorf("invalid match %q, expected center or overlap", entry.Match)
+	}
+	regions.Include, err = parsePolygons(entry.Include)
+	if err != nil {
+		return Regions{}, fmt.Errorf("include %w", err)
+	}
+	regions.Exclude, err = parsePolygons(entry.Exclude)
+	if err != nil {
+		return Regions{}, fmt.Errorf("exclude %w", err)
+	}
+	return regions, nil
+}
+
+// parsePolygons converts lists of x, y coordinates into polygons.
+func parsePolygons(entries [][][2]int) ([]Polygon, error) {
+	polygons := []Polygon{}
+	for i, entry := range entries {
+		if len(entry) < 3 {
+			return nil, fmt.Errorf("polygon %d: at least 3 points are required", i)
+		}
+		polygon := Polygon{}
+		for _, point := range entry {
+			polygon = append(polygon, image.Pt(point[0], point[1]))
+		}
+		polygons = append(polygons, polygon)
+	}
+	return polygons, nil
+}
+
+// bounds calculates the bounding rectangle of the include polygons.
+func (r Regions) bounds() image.Rectangle {
+	bounds := image.Rectangle{}
+	for _, polygon := range r.Include {
+		bounds = bounds.Union(polygon.bounds())
+	}
+	return bounds
+}
+
+// keep determines whether a detection with given box is reported.
+func (r Regions) keep(box Box) bool {
+	if r.Match == RegionMatchOverlap {
+		minOverlap := r.MinOverlap
+		if minOverlap <= 0 {
+			minOverlap = DefaultRegionMinOverlap
+		}
+		if len(r.Include) > 0 && polygonsOverlap(r.Include, box) < minOverlap {
+			return false
+		}
+		return len(r.Exclude) == 0 || polygonsOverlap(r.Exclude, box) < minOverlap
+	}
+
+	x, y := float64(box.MinX+box.MaxX)/2, float64(box.MinY+box.MaxY)/2
+	if len(r.Include) > 0 && !polygonsContain(r.Include, x, y) {
+		return false
+	}
+	return !polygonsContain(r.Exclude, x, y)
+}
+
+// filter drops the detections which are not kept.
+func (r Regions) filter(detections []ObjectDetection) []ObjectDetection {
+	result := []ObjectDetection{}
+	for _, detection := range detections {
+		if r.keep(detection.box()) {
+			result = append(result, detection)
+		}
+	}
+	return result
+}
+
+// polygonsContain determines whether the point lies within any of the polygons.
+func polygonsContain(polygons []Polygon, x, y float64) bool {
+	for _, polygon := range polygons {
+		if polygon.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// polygonsOverlap calculates the part of the box covered by the polygons.
+func polygonsOverlap(polygons []Polygon, box Box) float32 {
+	var overlap float64
+	for _, polygon := range polygons {
+		overlap += polygon.overlap(box)
+	}
+	return float32(math.Min(overlap, 1))
+}
+
+// bounds calculates the bounding rectangle of the polygon.
+func (p Polygon) bounds() image.Rectangle {
+	if len(p) == 0 {
+		return image.Rectangle{}
+	}
+	bounds := image.Rectangle{Min: p[0], Max: p[0]}
+	for _, point := range p[1:] {
+		bounds.Min.X = min(bounds.Min.X, point.X)
+		bounds.Min.Y = min(bounds.Min.Y, point.Y)
+		bounds.Max.X = max(bounds.Max.X, point.X)
+		bounds.Max.Y = max(bounds.Max.Y, point.Y)
+	}
+	return bounds
+}
+
+// contains determines whether the point lies within the polygon using ray casting.
+func (p Polygon) contains(x, y float64) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		ax, ay := float64(p[i].X), float64(p[i].Y)
+		bx, by := float64(p[j].X), float64(p[j].Y)
+		if (ay > y) != (by > y) && x < (bx-ax)*(y-ay)/(by-ay)+ax {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// overlap calculates the part of the box covered by the polygon, by clipping the polygon to the box.
+func (p Polygon) overlap(box Box) float64 {
+	if box.area() == 0 {
+		return 0
+	}
+	points := [][2]float64{}
+	for _, point := range p {
+		points = append(points, [2]float64{float64(point.X), float64(point.Y)})
+	}
+	points = clipAxis(points, 0, float64(box.MinX), true)
+	points = clipAxis(points, 0, float64(box.MaxX), false)
+	points = clipAxis(points, 1, float64(box.MinY), true)
+	points = clipAxis(points, 1, float64(box.MaxY), false)
+	return polygonArea(points) / float64(box.area())
+}
+
+// clipAxis clips the polygon to the half plane in which the coordinate along the axis is above
+// or below the limit.
+func clipAxis(points [][2]float64, axis int, limit float64, above bool) [][2]float64 {
+	inside := func(point [2]float64) bool {
+		if above {
+			return point[axis] >= limit
+		}
+		return point[axis] <= limit
+	}
+	intersect := func(a, b [2]float64) [2]float64 {
+		t := (limit - a[axis]) / (b[axis] - a[axis])
+		return [2]float64{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+	}
+
+	result := [][2]float64{}
+	for i, current := range points {
+		previous := points[(i+len(points)-1)%len(points)]
+		switch {
+		case inside(current):
+			if !inside(previous) {
+				result = append(result, intersect(previous, current))
+			}
+			result = append(result, current)
+		case inside(previous):
+			result = append(result, intersect(previous, current))
+		}
+	}
+	return result
+}
+
+// polygonArea calculates the area of the polygon using the shoelace formula.
+func polygonArea(points [][2]float64) float64 {
+	var sum float64
+	for i, current := range points {
+		next := points[(i+1)%len(points)]
+		sum += current[0]*next[1] - next[0]*current[1]
+	}
+	return math.Abs(sum) / 2
+}
+
+// RegionNet is a Net which only reports detections in the configured regions of the frame.
+type RegionNet struct {
+	detector
+	net     Net
+	regions Regions
+}
+
+// NewRegionNet creates a region net using given net for detecting objects, reporting only the
+// detections in the regions.
+func NewRegionNet(net Net, regions Regions) *RegionNet {
+	r := &RegionNet{net: net, regions: regions}
+	r.detector = detector{
+		detect: func(ctx context.Context, frame gocv.Mat, filter *Filter) ([]ObjectDetection, error) {
+			return r.detect(ctx, frame, r.regions, filter)
+		},
+		detectBatch: r.detectBatch,
+	}
+	return r
+}
+
+// Close closes the underlying net.
+func (r *RegionNet) Close() error {
+	return r.net.Close()
+}
+
+// GetDetectionsWithRegions retrieve predicted detections in given regions of the matrix, instead of the regions of the net.
+func (r *RegionNet) GetDetectionsWithRegions(frame gocv.Mat, regions Regions) ([]ObjectDetection, error) {
+	return r.detect(context.Background(), frame, regions, nil)
+}
+
+// GetDetectionsWithRegionsContext retrieve predicted detections in given regions of the matrix, instead of the
+// regions of the net, unless the context is done.
+func (r *RegionNet) GetDetectionsWithRegionsContext(ctx context.Context, frame gocv.Mat, regions Regions) ([]ObjectDetection, error) {
+	return r.detect(ctx, frame, regions, nil)
+}
+
+// detect detects the objects in the frame, optionally cropped to the regions, and drops the detections outside the regions.
+// Given filter is applied, or else the filter of the underlying net.
+func (r *RegionNet) detect(ctx context.Context, frame gocv.Mat, regions Regions, filter *Filter) ([]ObjectDetection, error) {
+	if !regions.Crop || len(regions.Include) == 0 {
+		detections, err := detectWithFilter(ctx, r.net, frame, filter)
+		if err != nil {
+			return nil, err
+		}
+		return regions.filter(detections), nil
+	}
+
+	crop := regions.bounds().Intersect(image.Rect(0, 0, frame.Cols(), frame.Rows()))
+	if crop.Empty() {
+		return []ObjectDetection{}, nil
+	}
+	detections, err := detectRegion(ctx, r.net, frame, crop, filter)
+	if err != nil {
+		return nil, err
+	}
+	return regions.filter(detections), nil
+}
+
+// detectBatch detects the objects in the regions of the frames. Frames which are not cropped are
+// detected as a batch by the underlying net.
+func (r *RegionNet) detectBatch(ctx context.Context, frames []gocv.Mat) ([][]ObjectDetection, error) {
+	if r.regions.Crop && len(r.regions.Include) > 0 {
+		return detectEach(ctx, frames, r.GetDetectionsContext)
+	}
+
+	result, err := r.net.GetDetectionsBatchContext(ctx, frames)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i] = r.regions.filter(result[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,203 @@
+package yolov3
+
+import (
+	"context"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *YoloTestSuite) TestLoadRegions() {
+	regions, err := LoadRegions("testdata/regions.json")
+	s.Require().NoError(err)
+	s.Equal(Regions{
+		Include:    []Polygon{{image.Pt(0, 0), image.Pt(100, 0), image.Pt(100, 100), image.Pt(0, 100)}},
+		Exclude:    []Polygon{{image.Pt(0, 0), image.Pt(50, 0), image.Pt(50, 50), image.Pt(0, 50)}},
+		Crop:       true,
+		Match:      RegionMatchOverlap,
+		MinOverlap: 0.3,
+	}, regions)
+
+	_, err = LoadRegions("testdata/nope.json")
+	s.Error(err)
+}
+
+func (s *YoloTestSuite) TestParseRegions() {
+	tests := []struct {
+		Name    string
+		Content string
+		Error   string
+	}{
+		{
+			Name:    "center match",
+			Content: `{"include": [[[0, 0], [1, 0], [1, 1]]], "match": "center"}`,
+		},
+		{
+			Name:    "invalid json",
+			Content: `{"include": `,
+			Error:   "unable to parse regions: unexpected end of JSON input",
+		},
+		{
+			Name:    "invalid match",
+			Content: `{"match": "inside"}`,
+			Error:   `invalid match "inside", expected center or overlap`,
+		},
+		{
+			Name:    "too few points",
+			Content: `{"exclude": [[[0, 0], [1, 0], [1, 1]], [[0, 0], [1, 1]]]}`,
+			Error:   "exclude polygon 1: at least 3 points are required",
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			_, err := parseRegions([]byte(test.Content))
+			if test.Error != "" {
+				s.EqualError(err, test.Error)
+				return
+			}
+			s.NoError(err)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestRegionsKeep() {
+	square := Polygon{image.Pt(0, 0), image.Pt(100, 0), image.Pt(100, 100), image.Pt(0, 100)}
+	triangle := Polygon{image.Pt(0, 0), image.Pt(100, 0), image.Pt(0, 100)}
+	tests := []struct {
+		Name    string
+		Regions Regions
+		Box     Box
+		Keep    bool
+	}{
+		{
+			Name:    "no regions",
+			Regions: Regions{},
+			Box:     Box{MinX: 500, MinY: 500, MaxX: 600, MaxY: 600},
+			Keep:    true,
+		},
+		{
+			Name:    "center inside include",
+			Regions: Regions{Include: []Polygon{square}},
+			Box:     Box{MinX: 80, MinY: 80, MaxX: 110, MaxY: 110},
+			Keep:    true,
+		},
+		{
+			Name:    "center outside include",
+			Regions: Regions{Include: []Polygon{square}},
+			Box:     Box{MinX: 90, MinY: 90, MaxX: 130, MaxY: 130},
+			Keep:    false,
+		},
+		{
+			Name:    "sub-pixel center inside include",
+			Regions: Regions{Include: []Polygon{square}},
+			// The center of the rounded box lies on the edge of the include
+			Box:  Box{MinX: 79.5, MinY: 80, MaxX: 119.6, MaxY: 110},
+			Keep: true,
+		},
+		{
+			Name:    "center inside exclude",
+			Regions: Regions{Include: []Polygon{square}, Exclude: []Polygon{triangle}},
+			Box:     Box{MinX: 10, MinY: 10, MaxX: 30, MaxY: 30},
+			Keep:    false,
+		},
+		{
+			Name:    "center outside exclude",
+			Regions: Regions{Include: []Polygon{square}, Exclude: []Polygon{triangle}},
+			Box:     Box{MinX: 70, MinY: 70, MaxX: 90, MaxY: 90},
+			Keep:    true,
+		},
+		{
+			Name:    "overlap below the default minimum",
+			Regions: Regions{Include: []Polygon{square}, Match: RegionMatchOverlap},
+			Box:     Box{MinX: 90, MinY: 0, MaxX: 130, MaxY: 40},
+			Keep:    false,
+		},
+		{
+			Name:    "overlap above the configured minimum",
+			Regions: Regions{Include: []Polygon{square}, Match: RegionMatchOverlap, MinOverlap: 0.2},
+			Box:     Box{MinX: 90, MinY: 0, MaxX: 130, MaxY: 40},
+			Keep:    true,
+		},
+		{
+			Name:    "overlap with multiple polygons",
+			Regions: Regions{Include: []Polygon{square, {image.Pt(100, 0), image.Pt(200, 0), image.Pt(200, 100)}}, Match: RegionMatchOverlap},
+			Box:     Box{MinX: 90, MinY: 0, MaxX: 130, MaxY: 40},
+			Keep:    true,
+		},
+		{
+			Name:    "overlap with triangle",
+			Regions: Regions{Include: []Polygon{triangle}, Match: RegionMatchOverlap},
+			Box:     Box{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100},
+			Keep:    true,
+		},
+		{
+			Name:    "overlap with exclude",
+			Regions: Regions{Exclude: []Polygon{triangle}, Match: RegionMatchOverlap},
+			Box:     Box{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100},
+			Keep:    false,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.Keep, test.Regions.keep(test.Box))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestRegionNet() {
+	include := Polygon{image.Pt(100, 50), image.Pt(300, 50), image.Pt(300, 150), image.Pt(100, 150)}
+	tests := []struct {
+		Name      string
+		Regions   Regions
+		PerCall   bool
+		FrameSize image.Point
+		Result    []ObjectDetection
+	}{
+		{
+			Name:      "filter",
+			Regions:   Regions{Include: []Polygon{include}},
+			FrameSize: image.Pt(400, 200),
+			Result:    []ObjectDetection{{ClassID: 1, BoundingBox: image.Rect(120, 60, 140, 80)}},
+		},
+		{
+			Name:      "crop",
+			Regions:   Regions{Include: []Polygon{include}, Crop: true},
+			FrameSize: image.Pt(200, 100),
+			// Detections in the crop are shifted into the frame
+			Result: withBoxes(image.Pt(400, 200), []ObjectDetection{{ClassID: 1, BoundingBox: image.Rect(120, 60, 140, 80)}}),
+		},
+		{
+			Name:      "per call",
+			Regions:   Regions{Exclude: []Polygon{include}},
+			PerCall:   true,
+			FrameSize: image.Pt(400, 200),
+			Result:    []ObjectDetection{{ClassID: 0, BoundingBox: image.Rect(0, 0, 10, 10)}},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			net := &fakeNet{detect: func(_ context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+				s.Equal(test.FrameSize, image.Pt(frame.Cols(), frame.Rows()))
+				if frame.Cols() == 200 {
+					// Cropped frame, in which the detection is relative to the crop
+					return []ObjectDetection{{ClassID: 1, BoundingBox: image.Rect(20, 10, 40, 30)}}, nil
+				}
+				return []ObjectDetection{
+					{ClassID: 0, BoundingBox: image.Rect(0, 0, 10, 10)},
+					{ClassID: 1, BoundingBox: image.Rect(120, 60, 140, 80)},
+				}, nil
+			}}
+			frame := gocv.NewMatWithSize(200, 400, gocv.MatTypeCV8UC3)
+
+			var detections []ObjectDetection
+			var err error
+			if test.PerCall {
+				detections, err = NewRegionNet(net, Regions{Include: []Polygon{include}}).GetDetectionsWithRegions(frame, test.Regions)
+			} else {
+				detections, err = NewRegionNet(net, test.Regions).GetDetections(frame)
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Result, detections)
+		})
+	}
+}
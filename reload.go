@@ -0,0 +1,227 @@
+package yolov3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// ReloadableNet is a Net of which the underlying net can be replaced at runtime, e.g. to pick up
+// retrained weights. Detections keep being served by the current net while a new net is loaded.
+type ReloadableNet struct {
+	load func() (Net, error)
+
+	// reloadMu serialises reloads
+	reloadMu sync.Mutex
+	// mu guards the current generation and the closed state
+	mu      sync.RWMutex
+	current *netGeneration
+	closed  bool
+}
+
+// netGeneration is a net together with the calls which are running on it.
+type netGeneration struct {
+	net    Net
+	active sync.WaitGroup
+}
+
+// warmer is implemented by nets which can be warmed up before serving detections.
+type warmer interface {
+	warmUp() error
+}
+
+// NewReloadableNet creates a reloadable net, the given load function is used for creating
+// the initial net and on every reload, e.g.:
+//
+//	net, err := yolov3.NewReloadableNet(func() (yolov3.Net, error) {
+//		return yolov3.NewNet("data/yolov3/yolov3.weights", "data/yolov3/yolov3.cfg", "data/yolov3/coco.names")
+//	})
+func NewReloadableNet(load func() (Net, error)) (*ReloadableNet, error) {
+	net, err := loadNet(load)
+	if err != nil {
+		return nil, err
+	}
+	return &ReloadableNet{
+		load:    load,
+		current: &netGeneration{net: net},
+	}, nil
+}
+
+// loadNet loads and warms up a new net.
+func loadNet(load func() (Net, error)) (Net, error) {
+	net, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if w, ok := net.(warmer); ok {
+		err = w.warmUp()
+		if err != nil {
+			return nil, errors.Join(err, net.Close())
+		}
+	}
+	return net, nil
+}
+
+// Reload loads and warms up a new net and swaps it in. Calls running on the previous net are
+// finished before it is closed. When loading fails the current net is kept.
+func (r *ReloadableNet) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	net, err := loadNet(r.load)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return errors.Join(ErrNetClosed, net.Close())
+	}
+	previous := r.current
+	r.current = &netGeneration{net: net}
+	r.mu.Unlock()
+
+	previous.active.Wait()
+	return previous.net.Close()
+}
+
+// Watch polls the given files every interval and reloads the net once a change of their
+// modification time or size has been stable for an interval. The result of each reload is
+// passed to onReload, if set. Watch blocks until the context is done.
+func (r *ReloadableNet) Watch(ctx context.Context, interval time.Duration, onReload func(error), paths ...string) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := statFiles(paths)
+	pending := last
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := statFiles(paths)
+		if current == last {
+			pending = current
+			continue
+		}
+		if current != pending {
+			// Wait for the files to be completely written
+			pending = current
+			continue
+		}
+		last = current
+		err := r.Reload()
+		if onReload != nil {
+			onReload(err)
+		}
+	}
+}
+
+// statFiles summarises the modification times and sizes of the given files, missing files are skipped.
+func statFiles(paths []string) string {
+	summary := ""
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			summary += path + ":missing;"
+			continue
+		}
+		summary += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return summary
+}
+
+// acquire retrieves the current generation, which should be released once the call is done.
+func (r *ReloadableNet) acquire() (*netGeneration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, ErrNetClosed
+	}
+	r.current.active.Add(1)
+	return r.current, nil
+}
+
+// Close waits for running calls to finish and closes the current net.
+func (r *ReloadableNet) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	current := r.current
+	r.mu.Unlock()
+
+	current.active.Wait()
+	return current.net.Close()
+}
+
+// GetDetections retrieve predicted detections from given matrix using the current net.
+func (r *ReloadableNet) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	g, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer g.active.Done()
+	return g.net.GetDetections(frame)
+}
+
+// GetDetectionsWithFilter allows you to detect objects using the current net, reporting the classes allowed by given filter.
+func (r *ReloadableNet) GetDetectionsWithFilter(frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	g, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer g.active.Done()
+	return g.net.GetDetectionsWithFilter(frame, filter)
+}
+
+// GetDetectionsContext retrieve predicted detections from given matrix using the current net, unless the context is done.
+func (r *ReloadableNet) GetDetectionsContext(ctx context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+	g, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer g.active.Done()
+	return g.net.GetDetectionsContext(ctx, frame)
+}
+
+// GetDetectionsWithFilterContext allows you to detect objects using the current net, reporting the classes allowed by given filter.
+func (r *ReloadableNet) GetDetectionsWithFilterContext(ctx context.Context, frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	g, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer g.active.Done()
+	return g.net.GetDetectionsWithFilterContext(ctx, frame, filter)
+}
+
+// GetDetectionsBatch retrieve predicted detections from given matrices in a single forward pass using the current net.
+func (r *ReloadableNet) GetDetectionsBatch(frames []gocv.Mat) ([][]ObjectDetection, error) {
+	g, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer g.active.Done()
+	return g.net.GetDetectionsBatch(frames)
+}
+
+// GetDetectionsBatchContext retrieve predicted detections from given matrices in a single forward pass using
+// the current net, unless the context is done.
+func (r *ReloadableNet) GetDetectionsBatchContext(ctx context.Context, frames []gocv.Mat) ([][]ObjectDetection, error) {
+	g, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer g.active.Done()
+	return g.net.GetDetectionsBatchContext(ctx, frames)
+}
@@ -0,0 +1,205 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/darknet"
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+)
+
+// newReloadTestNet creates a yolo net backed by a neural net mock, which returns the output
+// created by given function on every forward, including the warm up.
+func (s *YoloTestSuite) newReloadTestNet(output func() gocv.Mat, closed *atomic.Bool) Net {
+	controller := gomock.NewController(s.T())
+	neuralNetMock := mocks.NewMockNeuralNet(controller)
+	neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+	neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+	neuralNetMock.EXPECT().SetInput(gomock.Any(), gomock.Any()).AnyTimes()
+	neuralNetMock.EXPECT().ForwardLayers([]string{"yolo_16", "yolo_23"}).DoAndReturn(func([]string) []gocv.Mat {
+		return []gocv.Mat{output()}
+	}).AnyTimes()
+	neuralNetMock.EXPECT().Close().DoAndReturn(func() error {
+		closed.Store(true)
+		return nil
+	}).MaxTimes(1)
+
+	config := DefaultConfigForModel(ModelYoloV3Tiny)
+	s.Require().NoError(config.validate())
+	net, err := newYoloNet(neuralNetMock, labelsFromNames([]string{"laptop", "coffee"}), &darknet.Config{YoloLayers: []darknet.YoloLayer{{Classes: 2}}}, config)
+	s.Require().NoError(err)
+	return net
+}
+
+func (s *YoloTestSuite) TestReloadableNetReload() {
+	var firstClosed, secondClosed atomic.Bool
+	loads := 0
+	net, err := NewReloadableNet(func() (Net, error) {
+		loads++
+		if loads == 1 {
+			return s.newReloadTestNet(laptopDetection, &firstClosed), nil
+		}
+		return s.newReloadTestNet(coffeeDetection, &secondClosed), nil
+	})
+	s.Require().NoError(err)
+
+	frame := gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F)
+	detections, err := net.GetDetections(frame)
+	s.Require().NoError(err)
+	s.Require().Len(detections, 1)
+	s.Equal("laptop", detections[0].ClassName)
+
+	s.Require().NoError(net.Reload())
+	s.True(firstClosed.Load())
+
+	detections, err = net.GetDetectionsWithFilter(frame, Filter{})
+	s.Require().NoError(err)
+	s.Require().Len(detections, 1)
+	s.Equal("coffee", detections[0].ClassName)
+
+	s.NoError(net.Close())
+	s.True(secondClosed.Load())
+	s.NoError(net.Close())
+
+	_, err = net.GetDetections(frame)
+	s.EqualError(err, "net is closed")
+	s.EqualError(net.Reload(), "net is closed")
+}
+
+func (s *YoloTestSuite) TestReloadableNetReloadFailure() {
+	var closed atomic.Bool
+	loads := 0
+	net, err := NewReloadableNet(func() (Net, error) {
+		loads++
+		if loads == 1 {
+			return s.newReloadTestNet(laptopDetection, &closed), nil
+		}
+		return nil, fmt.Errorf("very broken")
+	})
+	s.Require().NoError(err)
+
+	s.EqualError(net.Reload(), "very broken")
+	s.False(closed.Load())
+
+	detections, err := net.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+	s.Require().NoError(err)
+	s.Len(detections, 1)
+	s.NoError(net.Close())
+}
+
+func (s *YoloTestSuite) TestNewReloadableNetLoadFailure() {
+	_, err := NewReloadableNet(func() (Net, error) {
+		return nil, fmt.Errorf("very broken")
+	})
+	s.EqualError(err, "very broken")
+}
+
+func (s *YoloTestSuite) TestReloadableNetReloadWaitsForRunningCalls() {
+	var firstClosed, secondClosed atomic.Bool
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var forwards atomic.Int32
+	loads := 0
+	net, err := NewReloadableNet(func() (Net, error) {
+		loads++
+		if loads == 1 {
+			return s.newReloadTestNet(func() gocv.Mat {
+				// The first forward is the warm up
+				if forwards.Add(1) == 2 {
+					close(started)
+					<-release
+				}
+				return laptopDetection()
+			}, &firstClosed), nil
+		}
+		return s.newReloadTestNet(coffeeDetection, &secondClosed), nil
+	})
+	s.Require().NoError(err)
+
+	detected := make(chan []ObjectDetection)
+	go func() {
+		detections, err := net.GetDetections(gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+		s.NoError(err)
+		detected <- detections
+	}()
+	<-started
+
+	reloaded := make(chan error)
+	go func() {
+		reloaded <- net.Reload()
+	}()
+
+	select {
+	case <-reloaded:
+		s.Fail("reload finished while a call was running on the previous net")
+	case <-time.After(50 * time.Millisecond):
+	}
+	s.False(firstClosed.Load())
+
+	close(release)
+	detections := <-detected
+	s.Require().Len(detections, 1)
+	s.Equal("laptop", detections[0].ClassName)
+	s.NoError(<-reloaded)
+	s.True(firstClosed.Load())
+	s.NoError(net.Close())
+}
+
+func (s *YoloTestSuite) TestReloadableNetWatch() {
+	weightsPath := filepath.Join(s.T().TempDir(), "yolov3.weights")
+	s.Require().NoError(os.WriteFile(weightsPath, []byte("weights"), 0o600))
+
+	var firstClosed, secondClosed atomic.Bool
+	var loads atomic.Int32
+	net, err := NewReloadableNet(func() (Net, error) {
+		if loads.Add(1) == 1 {
+			return s.newReloadTestNet(laptopDetection, &firstClosed), nil
+		}
+		return s.newReloadTestNet(coffeeDetection, &secondClosed), nil
+	})
+	s.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reloaded := make(chan error, 1)
+	watched := make(chan error)
+	go func() {
+		watched <- net.Watch(ctx, 5*time.Millisecond, func(err error) {
+			select {
+			case reloaded <- err:
+			default:
+			}
+		}, weightsPath)
+	}()
+
+	// Keep retraining until the watcher has picked up a change, as the watcher may not
+	// have recorded the initial state of the weights yet
+	weights := []byte("weights")
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+retrain:
+	for {
+		select {
+		case err := <-reloaded:
+			s.NoError(err)
+			break retrain
+		case <-ticker.C:
+			weights = append(weights, '!')
+			s.Require().NoError(os.WriteFile(weightsPath, weights, 0o600))
+		case <-timeout:
+			s.FailNow("net was not reloaded")
+		}
+	}
+	s.True(firstClosed.Load())
+
+	cancel()
+	s.ErrorIs(<-watched, context.Canceled)
+	s.NoError(net.Close())
+}
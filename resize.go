@@ -0,0 +1,130 @@
+package yolov3
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gocv.io/x/gocv"
+)
+
+// ResizeMode determines how frames are resized to the input size of the net.
+type ResizeMode int
+
+const (
+	// ResizeStretch stretches the frame to the input size, ignoring its aspect ratio
+	ResizeStretch ResizeMode = iota
+	// ResizeLetterbox scales the frame to fit the input size preserving its aspect ratio,
+	// the remainder of the input is padded
+	ResizeLetterbox
+	// ResizeCenterCrop scales the frame to cover the input size preserving its aspect ratio,
+	// the center of the frame is cropped to the input size
+	ResizeCenterCrop
+)
+
+// blobFromFrames creates the input blob of the net for given frames, the caller is responsible for closing it.
+func blobFromFrames(frames []gocv.Mat, inputSize image.Point, mode ResizeMode) gocv.Mat {
+	inputs := frames
+	if mode == ResizeLetterbox {
+		inputs = []gocv.Mat{}
+		for _, frame := range frames {
+			input := letterbox(frame, inputSize)
+			// nolint: errcheck
+			defer input.Close()
+			inputs = append(inputs, input)
+		}
+	}
+	crop := mode == ResizeCenterCrop
+	if len(inputs) == 1 {
+		return gocv.BlobFromImage(inputs[0], 1.0/255.0, inputSize, gocv.NewScalar(0, 0, 0, 0), true, crop)
+	}
+	blob := gocv.NewMat()
+	gocv.BlobFromImages(inputs, &blob, 1.0/255.0, inputSize, gocv.NewScalar(0, 0, 0, 0), true, crop, gocv.MatTypeCV32F)
+	return blob
+}
+
+// letterbox scales the frame to fit the input size and pads the remainder, the caller is responsible
+// for closing the result.
+func letterbox(frame gocv.Mat, inputSize image.Point) gocv.Mat {
+	resized, pad := letterboxGeometry(image.Pt(frame.Cols(), frame.Rows()), inputSize)
+	scaled := gocv.NewMat()
+	// nolint: errcheck
+	defer scaled.Close()
+	gocv.Resize(frame, &scaled, resized, 0, 0, gocv.InterpolationLinear)
+
+	return padFrame(scaled, pad.Y, inputSize.Y-resized.Y-pad.Y, pad.X, inputSize.X-resized.X-pad.X)
+}
+
+// padFrame pads the frame by given amount of pixels on each side, the caller is responsible for closing the result.
+func padFrame(frame gocv.Mat, top, bottom, left, right int) gocv.Mat {
+	// Pad using gray, as commonly done while training yolo models
+	gray := color.RGBA{R: 114, G: 114, B: 114}
+	padded := gocv.NewMat()
+	gocv.CopyMakeBorder(frame, &padded, top, bottom, left, right, gocv.BorderConstant, gray)
+	return padded
+}
+
+// letterboxGeometry calculates the size of the scaled frame and the padding before it.
+func letterboxGeometry(frameSize, inputSize image.Point) (image.Point, image.Point) {
+	scale := math.Min(float64(inputSize.X)/float64(frameSize.X), float64(inputSize.Y)/float64(frameSize.Y))
+	resized := image.Pt(int(math.Round(float64(frameSize.X)*scale)), int(math.Round(float64(frameSize.Y)*scale)))
+	pad := image.Pt((inputSize.X-resized.X)/2, (inputSize.Y-resized.Y)/2)
+	return resized, pad
+}
+
+// cropGeometry calculates the size of the scaled frame and the offset of the crop, as done by gocv.BlobFromImage.
+func cropGeometry(frameSize, inputSize image.Point) (image.Point, image.Point) {
+	scale := math.Max(float64(inputSize.X)/float64(frameSize.X), float64(inputSize.Y)/float64(frameSize.Y))
+	resized := image.Pt(int(math.Round(float64(frameSize.X)*scale)), int(math.Round(float64(frameSize.Y)*scale)))
+	// The offset is truncated by the integer division of OpenCV
+	offset := image.Pt((resized.X-inputSize.X)/2, (resized.Y-inputSize.Y)/2)
+	return resized, offset
+}
+
+// boxTransform maps the normalised bounding boxes of the net onto the frame, inverting the resize of the frame.
+type boxTransform struct {
+	scaleX, scaleY   float64
+	offsetX, offsetY float64
+}
+
+// newBoxTransform creates the transform for a frame of given size resized to the input size.
+func newBoxTransform(frameSize, inputSize image.Point, mode ResizeMode) boxTransform {
+	frameWidth, frameHeight := float64(frameSize.X), float64(frameSize.Y)
+	inputWidth, inputHeight := float64(inputSize.X), float64(inputSize.Y)
+	switch mode {
+	case ResizeLetterbox:
+		resized, pad := letterboxGeometry(frameSize, inputSize)
+		scaleX, scaleY := float64(resized.X)/frameWidth, float64(resized.Y)/frameHeight
+		return boxTransform{
+			scaleX:  inputWidth / scaleX,
+			scaleY:  inputHeight / scaleY,
+			offsetX: -float64(pad.X) / scaleX,
+			offsetY: -float64(pad.Y) / scaleY,
+		}
+	case ResizeCenterCrop:
+		resized, offset := cropGeometry(frameSize, inputSize)
+		scaleX, scaleY := float64(resized.X)/frameWidth, float64(resized.Y)/frameHeight
+		return boxTransform{
+			scaleX:  inputWidth / scaleX,
+			scaleY:  inputHeight / scaleY,
+			offsetX: float64(offset.X) / scaleX,
+			offsetY: float64(offset.Y) / scaleY,
+		}
+	default:
+		return boxTransform{scaleX: frameWidth, scaleY: frameHeight}
+	}
+}
+
+// box calculates the box in pixels of the frame for a box formatted as center x, center y,
+// width and height normalised to the input size.
+func (t boxTransform) box(box []float32) Box {
+	if len(box) < 4 {
+		return Box{}
+	}
+	return BoxFromCXCYWH([4]float32{
+		float32(float64(box[0])*t.scaleX + t.offsetX),
+		float32(float64(box[1])*t.scaleY + t.offsetY),
+		float32(float64(box[2]) * t.scaleX),
+		float32(float64(box[3]) * t.scaleY),
+	})
+}
@@ -0,0 +1,128 @@
+package yolov3
+
+import (
+	"image"
+
+	"github.com/golang/mock/gomock"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov3/internal/ml/mocks"
+)
+
+func (s *YoloTestSuite) TestBoxTransform() {
+	tests := []struct {
+		Name         string
+		FrameSize    image.Point
+		InputSize    image.Point
+		Mode         ResizeMode
+		Box          []float32
+		ExpectedRect image.Rectangle
+	}{
+		{
+			Name:         "normal bounding box calculation",
+			FrameSize:    image.Pt(2, 2),
+			InputSize:    image.Pt(416, 416),
+			Box:          []float32{1, 1, 1, 1},
+			ExpectedRect: image.Rect(1, 1, 3, 3),
+		},
+		{
+			Name:         "unexpected row",
+			FrameSize:    image.Pt(2, 2),
+			InputSize:    image.Pt(416, 416),
+			Box:          []float32{1, 1, 1},
+			ExpectedRect: image.Rect(0, 0, 0, 0),
+		},
+		{
+			Name:         "stretched wide frame",
+			FrameSize:    image.Pt(1920, 1080),
+			InputSize:    image.Pt(416, 416),
+			Box:          []float32{0.5, 0.5, 0.25, 0.5},
+			ExpectedRect: image.Rect(720, 270, 1200, 810),
+		},
+		{
+			Name:      "letterboxed wide frame",
+			FrameSize: image.Pt(1920, 1080),
+			InputSize: image.Pt(416, 416),
+			Mode:      ResizeLetterbox,
+			// The frame is scaled to 416x234 and padded by 91 pixels at the top and bottom,
+			// the box covers the right half of the scaled frame
+			Box:          []float32{0.75, 0.5, 0.5, 234.0 / 416},
+			ExpectedRect: image.Rect(960, 0, 1920, 1080),
+		},
+		{
+			Name:      "letterboxed tall frame",
+			FrameSize: image.Pt(100, 200),
+			InputSize: image.Pt(416, 416),
+			Mode:      ResizeLetterbox,
+			// The frame is scaled to 208x416 and padded by 104 pixels at the left and right
+			Box:          []float32{0.5, 0.25, 0.25, 0.5},
+			ExpectedRect: image.Rect(25, 0, 75, 100),
+		},
+		{
+			Name:      "center cropped wide frame",
+			FrameSize: image.Pt(200, 100),
+			InputSize: image.Pt(416, 416),
+			Mode:      ResizeCenterCrop,
+			// The frame is scaled to 832x416 and cropped by 208 pixels at the left and right
+			Box:          []float32{0.5, 0.5, 0.5, 1},
+			ExpectedRect: image.Rect(75, 0, 125, 100),
+		},
+		{
+			Name:      "center cropped frame edge",
+			FrameSize: image.Pt(200, 100),
+			InputSize: image.Pt(416, 416),
+			Mode:      ResizeCenterCrop,
+			Box:       []float32{0, 0.5, 0.5, 1},
+			// The left edge of the input is at a quarter of the frame
+			ExpectedRect: image.Rect(25, 0, 75, 100),
+		},
+		{
+			Name:      "center cropped odd difference",
+			FrameSize: image.Pt(5, 3),
+			InputSize: image.Pt(4, 3),
+			Mode:      ResizeCenterCrop,
+			// The frame is not scaled and cropped by 0 pixels at the left and 1 pixel at the right
+			Box:          []float32{0.5, 0.5, 0.5, 1},
+			ExpectedRect: image.Rect(1, 0, 3, 3),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			transform := newBoxTransform(test.FrameSize, test.InputSize, test.Mode)
+			s.Equal(test.ExpectedRect, transform.box(test.Box).Rect())
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestGetDetectionsLetterbox() {
+	controller := gomock.NewController(s.T())
+	neuralNetMock := mocks.NewMockNeuralNet(controller)
+	neuralNetMock.EXPECT().SetInput(gomock.Any(), "").Do(func(blob gocv.Mat, _ string) {
+		s.Equal([]int{1, 3, 416, 416}, blob.Size())
+	}).Times(1)
+	neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).DoAndReturn(func([]string) []gocv.Mat {
+		// A laptop covering the top half of the letterboxed 200x100 frame
+		output := gocv.NewMatWithSize(1, 7, gocv.MatTypeCV32F)
+		output.SetFloatAt(0, 0, 0.5)
+		output.SetFloatAt(0, 1, 0.375)
+		output.SetFloatAt(0, 2, 1)
+		output.SetFloatAt(0, 3, 0.25)
+		output.SetFloatAt(0, 5, 0.9)
+		return []gocv.Mat{output}
+	}).Times(1)
+
+	y := &yoloNet{
+		net:                 neuralNetMock,
+		labels:              labelsFromNames([]string{"laptop", "coffee"}),
+		DefaultInputWidth:   416,
+		DefaultInputHeight:  416,
+		resizeMode:          ResizeLetterbox,
+		confidenceThreshold: DefaultConfThreshold,
+		DefaultNMSThreshold: DefaultNMSThreshold,
+	}
+	detections, err := y.GetDetections(gocv.NewMatWithSize(100, 200, gocv.MatTypeCV8UC3))
+	s.Require().NoError(err)
+	s.Equal(withBoxes(image.Pt(200, 100), []ObjectDetection{
+		{ClassID: 0, ClassName: "laptop", BoundingBox: image.Rect(0, 0, 200, 50), Confidence: 0.9},
+	}), detections)
+}
@@ -0,0 +1,68 @@
+package yolov3
+
+import (
+	"sort"
+)
+
+// ClassScore is the score of a class for a detection.
+type ClassScore struct {
+	ClassID   int
+	ClassName string
+	Score     float32
+}
+
+// candidateClasses retrieves the classes of the scores for which detections are considered. In multi-label
+// mode these are all classes of which the score exceeds the lowest confidence threshold, otherwise only the
+// class with the highest score.
+func (y *yoloNet) candidateClasses(scores []float32) []int {
+	if len(scores) == 0 {
+		return nil
+	}
+	if !y.multiLabel {
+		classID, _ := getClassIDAndConfidence(scores)
+		return []int{classID}
+	}
+	threshold := y.minConfidenceThreshold()
+	classIDs := []int{}
+	for classID, score := range scores {
+		if score > threshold {
+			classIDs = append(classIDs, classID)
+		}
+	}
+	return classIDs
+}
+
+// classScores retrieves the configured amount of classes with the highest scores, in descending order of score.
+func (y *yoloNet) classScores(scores []float32) []ClassScore {
+	if y.topClasses <= 0 {
+		return nil
+	}
+	classIDs := make([]int, len(scores))
+	for i := range classIDs {
+		classIDs[i] = i
+	}
+	sort.SliceStable(classIDs, func(i, j int) bool {
+		return scores[classIDs[i]] > scores[classIDs[j]]
+	})
+	if len(classIDs) > y.topClasses {
+		classIDs = classIDs[:y.topClasses]
+	}
+
+	result := []ClassScore{}
+	for _, classID := range classIDs {
+		classScore := ClassScore{ClassID: classID, Score: scores[classID]}
+		if classID < len(y.labels) {
+			classScore.ClassName = y.labels[classID].Name
+		}
+		result = append(result, classScore)
+	}
+	return result
+}
+
+// scoreVector retrieves a copy of the scores, if enabled.
+func (y *yoloNet) scoreVector(scores []float32) []float32 {
+	if !y.keepScores {
+		return nil
+	}
+	return append([]float32{}, scores...)
+}
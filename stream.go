@@ -0,0 +1,191 @@
+package yolov3
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// StreamMode determines how a stream handles frames which arrive while a detection is running.
+type StreamMode int
+
+const (
+	// StreamLatestFrame keeps only the latest frame which arrived during a detection, older frames are dropped.
+	StreamLatestFrame StreamMode = iota
+	// StreamQueue queues frames up to the queue size, once the queue is full the sender is blocked.
+	StreamQueue
+)
+
+// StreamConfig can be used to customise the handling of frames by a stream.
+type StreamConfig struct {
+	Mode StreamMode
+	// QueueSize is the amount of frames queued in StreamQueue mode, defaults to one
+	QueueSize int
+}
+
+// StreamResult contains the detections of a frame of a stream.
+type StreamResult struct {
+	// Seq is the sequence number of the frame in order of arrival, starting at zero. Dropped frames
+	// are counted as well, such that gaps in the sequence numbers reveal dropped frames.
+	Seq uint64
+	// Timestamp at which the frame was received by the stream
+	Timestamp time.Time
+	// Frame the detections belong to, the receiver of the result is responsible for closing it
+	Frame      gocv.Mat
+	Detections []ObjectDetection
+	Err        error
+}
+
+// streamFrame is a frame received by the stream, which is waiting for detection.
+type streamFrame struct {
+	seq       uint64
+	timestamp time.Time
+	frame     gocv.Mat
+}
+
+// Stream detects objects in the frames received from given channel and sends a result per detected frame
+// on the returned channel, which is closed once the frames channel is closed and all frames are handled, or
+// the context is done.
+//
+// The stream takes ownership of the frames it receives. Frames which are dropped, or not detected since the
+// context is done, are closed by the stream. Detected frames are passed on in the result.
+func Stream(ctx context.Context, net Net, frames <-chan gocv.Mat, config StreamConfig) <-chan StreamResult {
+	results := make(chan StreamResult)
+	if config.Mode == StreamQueue {
+		size := config.QueueSize
+		if size <= 0 {
+			size = 1
+		}
+		queue := make(chan streamFrame, size)
+		go receiveFrames(ctx, frames, func(f streamFrame) bool {
+			select {
+			case queue <- f:
+				return true
+			case <-ctx.Done():
+				// nolint: errcheck
+				f.frame.Close()
+				return false
+			}
+		}, func() { close(queue) })
+		go func() {
+			defer close(results)
+			for f := range queue {
+				detectStreamFrame(ctx, net, f, results)
+			}
+		}()
+		return results
+	}
+
+	latest := &latestFrame{ready: make(chan struct{}, 1)}
+	go receiveFrames(ctx, frames, func(f streamFrame) bool {
+		latest.put(f)
+		return true
+	}, latest.close)
+	go func() {
+		defer close(results)
+		for {
+			_, open := <-latest.ready
+			if f, ok := latest.take(); ok {
+				detectStreamFrame(ctx, net, f, results)
+			}
+			if !open {
+				return
+			}
+		}
+	}()
+	return results
+}
+
+// receiveFrames numbers the received frames and passes them to put until the frames channel
+// is closed, the context is done or put returns false. Once done, the done function is called.
+func receiveFrames(ctx context.Context, frames <-chan gocv.Mat, put func(streamFrame) bool, done func()) {
+	defer done()
+	var seq uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame, ok := <-frames:
+			if !ok {
+				return
+			}
+			if !put(streamFrame{seq: seq, timestamp: time.Now(), frame: frame}) {
+				return
+			}
+			seq++
+		}
+	}
+}
+
+// detectStreamFrame detects the objects in the frame and sends the result, unless the context is done
+// in which case the frame is closed.
+func detectStreamFrame(ctx context.Context, net Net, f streamFrame, results chan<- StreamResult) {
+	if ctx.Err() != nil {
+		// nolint: errcheck
+		f.frame.Close()
+		return
+	}
+	detections, err := net.GetDetectionsContext(ctx, f.frame)
+	if ctx.Err() != nil {
+		// nolint: errcheck
+		f.frame.Close()
+		return
+	}
+	result := StreamResult{
+		Seq:        f.seq,
+		Timestamp:  f.timestamp,
+		Frame:      f.frame,
+		Detections: detections,
+		Err:        err,
+	}
+	select {
+	case results <- result:
+	case <-ctx.Done():
+		// nolint: errcheck
+		f.frame.Close()
+	}
+}
+
+// latestFrame holds the latest frame of a stream, replacing a frame which has not been taken yet.
+type latestFrame struct {
+	mu      sync.Mutex
+	frame   streamFrame
+	pending bool
+	// ready is signalled when a frame is put and closed once no more frames will be put
+	ready chan struct{}
+}
+
+// put replaces the pending frame by given frame, the replaced frame is closed.
+func (l *latestFrame) put(f streamFrame) {
+	l.mu.Lock()
+	if l.pending {
+		// nolint: errcheck
+		l.frame.frame.Close()
+	}
+	l.frame = f
+	l.pending = true
+	l.mu.Unlock()
+
+	select {
+	case l.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take retrieves the pending frame, if any.
+func (l *latestFrame) take() (streamFrame, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.pending {
+		return streamFrame{}, false
+	}
+	l.pending = false
+	return l.frame, true
+}
+
+// close signals that no more frames will be put.
+func (l *latestFrame) close() {
+	close(l.ready)
+}
@@ -0,0 +1,50 @@
+//go:build matprofile
+// +build matprofile
+
+package yolov3
+
+import (
+	"context"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *YoloTestSuite) TestStreamReleasesFrames() {
+	modes := []struct {
+		Name string
+		Mode StreamMode
+	}{
+		{Name: "latest frame", Mode: StreamLatestFrame},
+		{Name: "queue", Mode: StreamQueue},
+	}
+	for _, test := range modes {
+		s.Run(test.Name+" completed stream", func() {
+			count := gocv.MatProfile.Count()
+			frames := make(chan gocv.Mat)
+			results := Stream(context.Background(), rowsNet(), frames, StreamConfig{Mode: test.Mode})
+			go sendFrames(frames, 10)
+			for result := range results {
+				s.NoError(result.Frame.Close())
+			}
+			s.Equal(count, gocv.MatProfile.Count())
+		})
+		s.Run(test.Name+" cancelled stream", func() {
+			count := gocv.MatProfile.Count()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			frames := make(chan gocv.Mat)
+			results := Stream(ctx, rowsNet(), frames, StreamConfig{Mode: test.Mode, QueueSize: 4})
+			go sendFrames(frames, 10)
+			for result := range results {
+				s.NoError(result.Frame.Close())
+				// Cancel on the first result, as frames may be dropped in latest frame mode
+				cancel()
+			}
+			// Frames which have not been received by the stream remain owned by the sender
+			for frame := range frames {
+				s.NoError(frame.Close())
+			}
+			s.Equal(count, gocv.MatProfile.Count())
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+
+	"gocv.io/x/gocv"
+)
+
+// fakeNet is a Net of which the detections are provided by a function.
+type fakeNet struct {
+	detect func(ctx context.Context, frame gocv.Mat) ([]ObjectDetection, error)
+}
+
+func (f *fakeNet) Close() error {
+	return nil
+}
+
+func (f *fakeNet) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	return f.detect(context.Background(), frame)
+}
+
+func (f *fakeNet) GetDetectionsWithFilter(frame gocv.Mat, _ Filter) ([]ObjectDetection, error) {
+	return f.detect(context.Background(), frame)
+}
+
+func (f *fakeNet) GetDetectionsContext(ctx context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+	return f.detect(ctx, frame)
+}
+
+func (f *fakeNet) GetDetectionsWithFilterContext(ctx context.Context, frame gocv.Mat, _ Filter) ([]ObjectDetection, error) {
+	return f.detect(ctx, frame)
+}
+
+func (f *fakeNet) GetDetectionsBatch(frames []gocv.Mat) ([][]ObjectDetection, error) {
+	return f.GetDetectionsBatchContext(context.Background(), frames)
+}
+
+func (f *fakeNet) GetDetectionsBatchContext(ctx context.Context, frames []gocv.Mat) ([][]ObjectDetection, error) {
+	return detectEach(ctx, frames, f.detect)
+}
+
+// rowsNet is a fake net which detects a single object of which the class id is the amount of rows of the frame.
+func rowsNet() *fakeNet {
+	return &fakeNet{detect: func(_ context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+		return []ObjectDetection{{ClassID: frame.Rows()}}, nil
+	}}
+}
+
+// sendFrames sends frames with 1 up to n rows and closes the channel.
+func sendFrames(frames chan<- gocv.Mat, n int) {
+	for i := 1; i <= n; i++ {
+		frames <- gocv.NewMatWithSize(i, 1, gocv.MatTypeCV8UC3)
+	}
+	close(frames)
+}
+
+func (s *YoloTestSuite) TestStreamQueue() {
+	frames := make(chan gocv.Mat)
+	results := Stream(context.Background(), rowsNet(), frames, StreamConfig{Mode: StreamQueue, QueueSize: 2})
+	go sendFrames(frames, 5)
+
+	seq := uint64(0)
+	for result := range results {
+		s.NoError(result.Err)
+		s.Equal(seq, result.Seq)
+		s.Equal([]ObjectDetection{{ClassID: int(seq) + 1}}, result.Detections)
+		s.Equal(int(seq)+1, result.Frame.Rows())
+		s.False(result.Timestamp.IsZero())
+		s.NoError(result.Frame.Close())
+		seq++
+	}
+	s.Equal(uint64(5), seq)
+}
+
+func (s *YoloTestSuite) TestStreamLatestFrame() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	net := rowsNet()
+	detect := net.detect
+	net.detect = func(ctx context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+		if frame.Rows() == 1 {
+			close(started)
+			<-release
+		}
+		return detect(ctx, frame)
+	}
+
+	frames := make(chan gocv.Mat)
+	results := Stream(context.Background(), net, frames, StreamConfig{})
+	frames <- gocv.NewMatWithSize(1, 1, gocv.MatTypeCV8UC3)
+	<-started
+	// The frames arriving during the detection of the first frame replace each other
+	for i := 2; i <= 5; i++ {
+		frames <- gocv.NewMatWithSize(i, 1, gocv.MatTypeCV8UC3)
+	}
+	close(frames)
+	close(release)
+
+	received := []StreamResult{}
+	for result := range results {
+		s.NoError(result.Err)
+		s.NoError(result.Frame.Close())
+		received = append(received, result)
+	}
+	s.Require().GreaterOrEqual(len(received), 2)
+	s.LessOrEqual(len(received), 3)
+	s.Equal(uint64(0), received[0].Seq)
+	s.Equal(uint64(4), received[len(received)-1].Seq)
+	s.Equal([]ObjectDetection{{ClassID: 5}}, received[len(received)-1].Detections)
+}
+
+func (s *YoloTestSuite) TestStreamDetectionError() {
+	net := &fakeNet{detect: func(context.Context, gocv.Mat) ([]ObjectDetection, error) {
+		return nil, fmt.Errorf("very broken")
+	}}
+	frames := make(chan gocv.Mat)
+	results := Stream(context.Background(), net, frames, StreamConfig{Mode: StreamQueue})
+	go sendFrames(frames, 2)
+
+	failures := 0
+	for result := range results {
+		s.EqualError(result.Err, "very broken")
+		s.NoError(result.Frame.Close())
+		failures++
+	}
+	s.Equal(2, failures)
+}
+
+func (s *YoloTestSuite) TestStreamCancel() {
+	for _, mode := range []StreamMode{StreamLatestFrame, StreamQueue} {
+		s.Run(fmt.Sprintf("mode %d", mode), func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			net := &fakeNet{detect: func(ctx context.Context, _ gocv.Mat) ([]ObjectDetection, error) {
+				cancel()
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}}
+			frames := make(chan gocv.Mat, 1)
+			results := Stream(ctx, net, frames, StreamConfig{Mode: mode})
+			frames <- gocv.NewMatWithSize(1, 1, gocv.MatTypeCV8UC3)
+
+			// The frame is closed by the stream, as the context is done during the detection
+			for range results {
+				s.Fail("result received after the context is done")
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package yolov3
+
+import (
+	"context"
+	"image"
+	"sort"
+
+	"gocv.io/x/gocv"
+)
+
+// Default constants for tiled detection.
+const (
+	DefaultTileOverlap        float32 = 0.2
+	DefaultTileMergeThreshold float32 = 0.5
+)
+
+// TileConfig can be used to customise the tiling of frames by a TiledNet.
+type TileConfig struct {
+	// TileWidth & TileHeight are the size of the tiles, defaulting to DefaultInputWidth & DefaultInputHeight
+	TileWidth  int
+	TileHeight int
+	// Overlap of adjacent tiles as a fraction of the tile size, defaults to DefaultTileOverlap
+	Overlap float32
+	// FullFrame additionally detects objects on the full frame, such that large objects are detected as well
+	FullFrame bool
+	// MergeThreshold is the minimum intersection over the smaller of two boxes of the same class detected
+	// on overlapping tiles for them to be merged, defaults to DefaultTileMergeThreshold
+	MergeThreshold float32
+}
+
+// TiledNet is a Net which splits frames into overlapping tiles and detects objects on each tile,
+// such that small objects in high resolution frames are not lost when downscaling the frame to the
+// input size of the net. Detections across the border of overlapping tiles are merged.
+type TiledNet struct {
+	detector
+	net    Net
+	config TileConfig
+}
+
+// NewTiledNet creates a tiled net using given net for detecting objects on the tiles.
+func NewTiledNet(net Net, config TileConfig) *TiledNet {
+	if config.TileWidth <= 0 {
+		config.TileWidth = DefaultInputWidth
+	}
+	if config.TileHeight <= 0 {
+		config.TileHeight = DefaultInputHeight
+	}
+	if config.Overlap <= 0 || config.Overlap >= 1 {
+		config.Overlap = DefaultTileOverlap
+	}
+	if config.MergeThreshold <= 0 {
+		config.MergeThreshold = DefaultTileMergeThreshold
+	}
+	t := &TiledNet{net: net, config: config}
+	t.detector = detector{detect: t.detect}
+	return t
+}
+
+// Close closes the underlying net.
+func (t *TiledNet) Close() error {
+	return t.net.Close()
+}
+
+// detect detects the objects in the tiles of the frame, applying given filter or else the filter of the underlying net.
+func (t *TiledNet) detect(ctx context.Context, frame gocv.Mat, filter *Filter) ([]ObjectDetection, error) {
+	frameSize := image.Pt(frame.Cols(), frame.Rows())
+	detections := []tileDetection{}
+	for _, tile := range tiles(frameSize, image.Pt(t.config.TileWidth, t.config.TileHeight), t.config.Overlap) {
+		tileDetections, err := detectRegion(ctx, t.net, frame, tile, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, detection := range tileDetections {
+			detections = append(detections, tileDetection{ObjectDetection: detection, tile: tile})
+		}
+	}
+
+	if t.config.FullFrame {
+		frameDetections, err := detectWithFilter(ctx, t.net, frame, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, detection := range frameDetections {
+			detections = append(detections, tileDetection{ObjectDetection: detection})
+		}
+	}
+
+	return mergeDetections(detections, t.config.MergeThreshold, frameSize), nil
+}
+
+// detectRegion detects the objects in a region of the frame using the net and shifts them into frame coordinates.
+func detectRegion(ctx context.Context, net Net, frame gocv.Mat, region image.Rectangle, filter *Filter) ([]ObjectDetection, error) {
+	tile := frame.Region(region)
+	// nolint: errcheck
+	defer tile.Close()
+
+	detections, err := detectWithFilter(ctx, net, tile, filter)
+	if err != nil {
+		return nil, err
+	}
+	frameSize := image.Pt(frame.Cols(), frame.Rows())
+	for i := range detections {
+		detections[i].setBox(detections[i].box().Add(region.Min), frameSize)
+	}
+	return detections, nil
+}
+
+// tiles splits a frame of given size into overlapping tiles. The last tile of each row and column
+// is aligned with the edge of the frame, frames smaller than a tile consist of a single tile.
+func tiles(frameSize, tileSize image.Point, overlap float32) []image.Rectangle {
+	xs := tileOffsets(frameSize.X, tileSize.X, overlap)
+	ys := tileOffsets(frameSize.Y, tileSize.Y, overlap)
+	result := []image.Rectangle{}
+	for _, y := range ys {
+		for _, x := range xs {
+			tile := image.Rect(x, y, x+tileSize.X, y+tileSize.Y)
+			result = append(result, tile.Intersect(image.Rect(0, 0, frameSize.X, frameSize.Y)))
+		}
+	}
+	return result
+}
+
+// tileOffsets calculates the offsets of the tiles along a single axis.
+func tileOffsets(frameSize, tileSize int, overlap float32) []int {
+	if frameSize <= tileSize {
+		return []int{0}
+	}
+	step := int(float32(tileSize) * (1 - overlap))
+	if step < 1 {
+		step = 1
+	}
+	offsets := []int{}
+	for offset := 0; offset+tileSize < frameSize; offset += step {
+		offsets = append(offsets, offset)
+	}
+	return append(offsets, frameSize-tileSize)
+}
+
+// tileDetection is a detection in frame coordinates together with the tile it was detected on,
+// detections of the full frame have an empty tile.
+type tileDetection struct {
+	ObjectDetection
+	tile image.Rectangle
+}
+
+// mergeDetections merges detections of the same class across the border of overlapping tiles of which the
+// intersection over the smaller box exceeds the threshold, such that objects cut by the border of a tile result
+// in a single detection. Detections of the same tile or the full frame have already been suppressed by the net
+// and are not merged. Merged detections keep the highest confidence and cover the union of the merged boxes.
+func mergeDetections(detections []tileDetection, threshold float32, frameSize image.Point) []ObjectDetection {
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].Confidence > detections[j].Confidence
+	})
+	merged := []ObjectDetection{}
+	used := make([]bool, len(detections))
+	for i := range detections {
+		if used[i] {
+			continue
+		}
+		detection := detections[i].ObjectDetection
+		cluster := []tileDetection{detections[i]}
+		for j := i + 1; j < len(detections); j++ {
+			if used[j] || detections[j].ClassID != detection.ClassID || !acrossTileBorder(cluster, detections[j]) {
+				continue
+			}
+			if detection.box().intersectionOverSmaller(detections[j].box()) > threshold {
+				detection.setBox(detection.box().Union(detections[j].box()), frameSize)
+				cluster = append(cluster, detections[j])
+				used[j] = true
+			}
+		}
+		merged = append(merged, detection)
+	}
+	return merged
+}
+
+// acrossTileBorder determines whether the detection is on another tile than the detections of the cluster, and
+// whether it lies in the overlap of its tile with the tile of one of the detections, together with that detection.
+func acrossTileBorder(cluster []tileDetection, detection tileDetection) bool {
+	for _, c := range cluster {
+		if c.tile == detection.tile {
+			return false
+		}
+	}
+	for _, c := range cluster {
+		overlap := BoxFromRect(c.tile.Intersect(detection.tile))
+		if overlap.area() > 0 && c.box().intersectionOverSmaller(overlap) > 0 && detection.box().intersectionOverSmaller(overlap) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *YoloTestSuite) TestTiles() {
+	tests := []struct {
+		Name      string
+		FrameSize image.Point
+		TileSize  image.Point
+		Overlap   float32
+		Expected  []image.Rectangle
+	}{
+		{
+			Name:      "last tiles aligned with the frame edges",
+			FrameSize: image.Pt(1000, 500),
+			TileSize:  image.Pt(416, 416),
+			Overlap:   0.2,
+			Expected: []image.Rectangle{
+				image.Rect(0, 0, 416, 416), image.Rect(332, 0, 748, 416), image.Rect(584, 0, 1000, 416),
+				image.Rect(0, 84, 416, 500), image.Rect(332, 84, 748, 500), image.Rect(584, 84, 1000, 500),
+			},
+		},
+		{
+			Name:      "frame smaller than a tile",
+			FrameSize: image.Pt(300, 200),
+			TileSize:  image.Pt(416, 416),
+			Overlap:   0.2,
+			Expected:  []image.Rectangle{image.Rect(0, 0, 300, 200)},
+		},
+		{
+			Name:      "frame of exactly one tile",
+			FrameSize: image.Pt(416, 416),
+			TileSize:  image.Pt(416, 416),
+			Overlap:   0.2,
+			Expected:  []image.Rectangle{image.Rect(0, 0, 416, 416)},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.Expected, tiles(test.FrameSize, test.TileSize, test.Overlap))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestTiledNet() {
+	// A frame of 800x416 is split into tiles starting at x 0, 208 and 384. The laptop at
+	// 400-440 is cut by the border of the first tile, the coffee is only in the first tile.
+	tileDetections := [][]ObjectDetection{
+		{
+			{ClassID: 0, ClassName: "laptop", Confidence: 0.6, BoundingBox: image.Rect(400, 100, 416, 140)},
+			{ClassID: 1, ClassName: "coffee", Confidence: 0.7, BoundingBox: image.Rect(10, 10, 30, 30)},
+		},
+		{{ClassID: 0, ClassName: "laptop", Confidence: 0.9, BoundingBox: image.Rect(192, 100, 232, 140)}},
+		{{ClassID: 0, ClassName: "laptop", Confidence: 0.8, BoundingBox: image.Rect(16, 100, 56, 140)}},
+		// Full frame
+		{{ClassID: 0, ClassName: "laptop", Confidence: 0.95, BoundingBox: image.Rect(500, 0, 800, 416)}},
+	}
+	tests := []struct {
+		Name      string
+		FullFrame bool
+		Calls     int
+		Result    []ObjectDetection
+	}{
+		{
+			Name:  "tiles only",
+			Calls: 3,
+			Result: withBoxes(image.Pt(800, 416), []ObjectDetection{
+				{ClassID: 0, ClassName: "laptop", Confidence: 0.9, BoundingBox: image.Rect(400, 100, 440, 140)},
+				{ClassID: 1, ClassName: "coffee", Confidence: 0.7, BoundingBox: image.Rect(10, 10, 30, 30)},
+			}),
+		},
+		{
+			Name:      "tiles and full frame",
+			FullFrame: true,
+			Calls:     4,
+			// The detection of the full frame is reported as is
+			Result: append([]ObjectDetection{
+				{ClassID: 0, ClassName: "laptop", Confidence: 0.95, BoundingBox: image.Rect(500, 0, 800, 416)},
+			}, withBoxes(image.Pt(800, 416), []ObjectDetection{
+				{ClassID: 0, ClassName: "laptop", Confidence: 0.9, BoundingBox: image.Rect(400, 100, 440, 140)},
+				{ClassID: 1, ClassName: "coffee", Confidence: 0.7, BoundingBox: image.Rect(10, 10, 30, 30)},
+			})...),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			calls := 0
+			net := &fakeNet{detect: func(_ context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+				if calls < 3 {
+					s.Equal(416, frame.Cols())
+				} else {
+					s.Equal(800, frame.Cols())
+				}
+				detections := append([]ObjectDetection{}, tileDetections[calls]...)
+				calls++
+				return detections, nil
+			}}
+			tiled := NewTiledNet(net, TileConfig{TileWidth: 416, TileHeight: 416, Overlap: 0.5, FullFrame: test.FullFrame})
+
+			detections, err := tiled.GetDetections(gocv.NewMatWithSize(416, 800, gocv.MatTypeCV8UC3))
+			s.Require().NoError(err)
+			s.Equal(test.Calls, calls)
+			s.Equal(test.Result, detections)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestMergeDetections() {
+	left, right := image.Rect(0, 0, 60, 100), image.Rect(40, 0, 100, 100)
+	detection := func(confidence float32, box Box, tile image.Rectangle) tileDetection {
+		d := tileDetection{ObjectDetection: ObjectDetection{ClassName: "laptop", Confidence: confidence}, tile: tile}
+		d.setBox(box, image.Pt(100, 100))
+		return d
+	}
+	tests := []struct {
+		Name       string
+		Detections []tileDetection
+		Expected   []tileDetection
+	}{
+		{
+			Name: "across the border of overlapping tiles",
+			Detections: []tileDetection{
+				detection(0.9, Box{MinX: 45, MinY: 0, MaxX: 60, MaxY: 10}, left),
+				detection(0.8, Box{MinX: 45, MinY: 0, MaxX: 70, MaxY: 10}, right),
+			},
+			Expected: []tileDetection{detection(0.9, Box{MinX: 45, MinY: 0, MaxX: 70, MaxY: 10}, left)},
+		},
+		{
+			Name: "sub-pixel boxes",
+			Detections: []tileDetection{
+				detection(0.9, Box{MinX: 40, MinY: 0, MaxX: 50, MaxY: 10}, left),
+				// Intersection over the smaller box of 0.54, while the bounding boxes have an intersection of 0.5
+				detection(0.8, Box{MinX: 44.6, MinY: 0, MaxX: 60, MaxY: 10}, right),
+			},
+			Expected: []tileDetection{detection(0.9, Box{MinX: 40, MinY: 0, MaxX: 60, MaxY: 10}, left)},
+		},
+		{
+			Name: "same tile",
+			Detections: []tileDetection{
+				detection(0.9, Box{MinX: 0, MinY: 0, MaxX: 10, MaxY: 10}, left),
+				detection(0.8, Box{MinX: 0, MinY: 0, MaxX: 10, MaxY: 20}, left),
+			},
+			Expected: []tileDetection{
+				detection(0.9, Box{MinX: 0, MinY: 0, MaxX: 10, MaxY: 10}, left),
+				detection(0.8, Box{MinX: 0, MinY: 0, MaxX: 10, MaxY: 20}, left),
+			},
+		},
+		{
+			Name: "full frame",
+			Detections: []tileDetection{
+				detection(0.9, Box{MinX: 45, MinY: 0, MaxX: 60, MaxY: 10}, image.Rectangle{}),
+				detection(0.8, Box{MinX: 45, MinY: 0, MaxX: 70, MaxY: 10}, right),
+			},
+			Expected: []tileDetection{
+				detection(0.9, Box{MinX: 45, MinY: 0, MaxX: 60, MaxY: 10}, image.Rectangle{}),
+				detection(0.8, Box{MinX: 45, MinY: 0, MaxX: 70, MaxY: 10}, right),
+			},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			expected := []ObjectDetection{}
+			for _, d := range test.Expected {
+				expected = append(expected, d.ObjectDetection)
+			}
+			s.Equal(expected, mergeDetections(test.Detections, 0.5, image.Pt(100, 100)))
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestTiledNetError() {
+	net := &fakeNet{detect: func(context.Context, gocv.Mat) ([]ObjectDetection, error) {
+		return nil, fmt.Errorf("very broken")
+	}}
+	_, err := NewTiledNet(net, TileConfig{}).GetDetections(gocv.NewMatWithSize(1000, 1000, gocv.MatTypeCV8UC3))
+	s.EqualError(err, "very broken")
+}
@@ -0,0 +1,61 @@
+package yolov3
+
+import (
+	"context"
+
+	"gocv.io/x/gocv"
+)
+
+// detector implements the detection methods of a Net using a single detect func, for the nets
+// wrapping other nets. A nil filter applies the default filter of the wrapping net.
+type detector struct {
+	detect func(ctx context.Context, frame gocv.Mat, filter *Filter) ([]ObjectDetection, error)
+	// detectBatch detects the objects in a batch of frames, defaults to detecting each frame in order
+	detectBatch func(ctx context.Context, frames []gocv.Mat) ([][]ObjectDetection, error)
+}
+
+// GetDetections retrieve predicted detections from given matrix.
+func (d detector) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	return d.detect(context.Background(), frame, nil)
+}
+
+// GetDetectionsWithFilter allows you to detect objects in given matrix, reporting the classes allowed by given filter.
+func (d detector) GetDetectionsWithFilter(frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	return d.detect(context.Background(), frame, &filter)
+}
+
+// GetDetectionsContext retrieve predicted detections from given matrix, unless the context is done.
+func (d detector) GetDetectionsContext(ctx context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+	return d.detect(ctx, frame, nil)
+}
+
+// GetDetectionsWithFilterContext allows you to detect objects in given matrix, reporting the classes allowed by given filter.
+func (d detector) GetDetectionsWithFilterContext(ctx context.Context, frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	return d.detect(ctx, frame, &filter)
+}
+
+// GetDetectionsBatch retrieve predicted detections from given matrices.
+func (d detector) GetDetectionsBatch(frames []gocv.Mat) ([][]ObjectDetection, error) {
+	return d.GetDetectionsBatchContext(context.Background(), frames)
+}
+
+// GetDetectionsBatchContext retrieve predicted detections from given matrices, unless the context is done.
+func (d detector) GetDetectionsBatchContext(ctx context.Context, frames []gocv.Mat) ([][]ObjectDetection, error) {
+	if d.detectBatch != nil {
+		return d.detectBatch(ctx, frames)
+	}
+	return detectEach(ctx, frames, d.GetDetectionsContext)
+}
+
+// detectEach detects the objects in each of the frames in order, stopping at the first error.
+func detectEach(ctx context.Context, frames []gocv.Mat, detect func(context.Context, gocv.Mat) ([]ObjectDetection, error)) ([][]ObjectDetection, error) {
+	result := [][]ObjectDetection{}
+	for _, frame := range frames {
+		detections, err := detect(ctx, frame)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, detections)
+	}
+	return result, nil
+}
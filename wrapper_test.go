@@ -0,0 +1,36 @@
+package yolov3
+
+import (
+	"context"
+	"fmt"
+
+	"gocv.io/x/gocv"
+)
+
+func (s *YoloTestSuite) TestDetector() {
+	filters := []*Filter{}
+	d := detector{detect: func(_ context.Context, frame gocv.Mat, filter *Filter) ([]ObjectDetection, error) {
+		filters = append(filters, filter)
+		if frame.Rows() == 0 {
+			return nil, fmt.Errorf("empty frame")
+		}
+		return []ObjectDetection{{ClassID: frame.Rows()}}, nil
+	}}
+	frame := gocv.NewMatWithSize(2, 2, gocv.MatTypeCV8UC3)
+	defer frame.Close()
+	filter := Filter{IncludeNames: []string{"laptop"}}
+
+	_, err := d.GetDetections(frame)
+	s.Require().NoError(err)
+	_, err = d.GetDetectionsWithFilterContext(context.Background(), frame, filter)
+	s.Require().NoError(err)
+	s.Equal([]*Filter{nil, &filter}, filters)
+
+	result, err := d.GetDetectionsBatch([]gocv.Mat{frame, frame})
+	s.Require().NoError(err)
+	s.Equal([][]ObjectDetection{{{ClassID: 2}}, {{ClassID: 2}}}, result)
+
+	_, err = d.GetDetectionsBatch([]gocv.Mat{frame, {}, frame})
+	s.EqualError(err, "empty frame")
+	s.Len(filters, 6, "detecting stops at the first error")
+}
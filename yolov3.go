@@ -10,14 +10,21 @@
 package yolov3
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"gocv.io/x/gocv"
 
+	"github.com/wimspaargaren/yolov3/darknet"
 	"github.com/wimspaargaren/yolov3/internal/ml"
 )
 
@@ -32,117 +39,407 @@ const (
 
 // Config can be used to customise the settings of the neural network used for object detection.
 type Config struct {
-	// InputWidth & InputHeight are used to determine the input size of the image for the network
+	// ModelKind determines the topology of the loaded model, used for defaulting the input size and output layers
+	ModelKind ModelKind
+	// InputWidth & InputHeight are used to determine the input size of the image for the network.
+	// When left empty they default to the [net] section of a Darknet config, or else the model kind.
 	InputWidth  int
 	InputHeight int
+	// ResizeMode determines how frames are resized to the input size, defaults to stretching
+	ResizeMode ResizeMode
+	// ClipBoxes clips the bounding boxes to the frame, by default boxes may extend beyond its edges
+	ClipBoxes bool
+	// OutputDecoder decodes the output layers of the net, defaults to the decoder of the model kind
+	OutputDecoder OutputDecoder
 	// ConfidenceThreshold can be used to determine the minimum confidence before an object is considered to be "detected"
 	ConfidenceThreshold float32
+	// ClassConfidenceThresholds overrides the confidence threshold per class, keyed by class name or class id,
+	// e.g. {"person": 0.3, "67": 0.8}. Classes without a threshold fall back to the ConfidenceThreshold.
+	ClassConfidenceThresholds map[string]float32
+	// TopClasses is the amount of classes with the highest scores reported per detection, none by default
+	TopClasses int
+	// KeepScores reports the scores of all classes per detection
+	KeepScores bool
+	// MultiLabel reports a detection for each class of which the score exceeds the confidence threshold,
+	// instead of only for the class with the highest score
+	MultiLabel bool
+	// Filter determines which classes are reported by default, it can be overridden per call
+	Filter Filter
 	// Non-maximum suppression threshold used for removing overlapping bounding boxes
 	NMSThreshold float32
+	// NMSStrategy determines how overlapping bounding boxes are suppressed, defaults to suppressing per class
+	// rather than the class-agnostic suppression of previous versions
+	NMSStrategy NMSStrategy
+	// SoftNMSSigma is the sigma of the Gaussian decay of NMSSoftGaussian, defaults to DefaultSoftNMSSigma
+	SoftNMSSigma float32
 
 	// Type on which the network will be executed
 	NetTargetType  gocv.NetTargetType
 	NetBackendType gocv.NetBackendType
 
-	// NewNet function can be used to inject a custom neural net
+	// InputName overrides the name of the input blob. When left empty the blob is
+	// set on the first input of the network.
+	InputName string
+	// OutputLayers overrides the names of the layers which are forwarded. When left
+	// empty the output layers of a Darknet config are used, or else those of the model
+	// kind, or the unconnected output layers of the network in case of a custom model.
+	OutputLayers []string
+
+	// NewNet function can be used to inject a custom neural net created from the paths of the model files
 	NewNet func(weightsPath, configPath string) ml.NeuralNet
+	// NewNetFromBytes function can be used to inject a custom neural net loaded from memory
+	NewNetFromBytes func(framework string, weights, netConfig []byte) (ml.NeuralNet, error)
+	// Framework of the model used when loading the net from memory, e.g. "darknet" or "onnx".
+	// Defaults to the framework of the model kind.
+	Framework string
 }
 
 // validate ensures that the basic fields of the config are set
-func (c *Config) validate() {
+func (c *Config) validate() error {
+	preset, err := c.ModelKind.preset()
+	if err != nil {
+		return err
+	}
 	if c.NewNet == nil {
 		c.NewNet = initializeNet
 	}
+	if c.NewNetFromBytes == nil {
+		c.NewNetFromBytes = initializeNetFromBytes
+	}
+	c.Framework = c.framework()
 	if c.InputWidth == 0 {
-		c.InputWidth = DefaultInputWidth
+		c.InputWidth = preset.inputWidth
 	}
 	if c.InputHeight == 0 {
-		c.InputHeight = DefaultInputHeight
+		c.InputHeight = preset.inputHeight
+	}
+	if len(c.OutputLayers) == 0 {
+		c.OutputLayers = preset.outputLayers
+	}
+	if c.OutputDecoder == nil {
+		c.OutputDecoder = preset.decoder
+	}
+	return nil
+}
+
+// framework returns the framework of the model, defaulting to the framework of the model kind.
+func (c *Config) framework() string {
+	if c.Framework != "" {
+		return c.Framework
 	}
+	preset, _ := c.ModelKind.preset()
+	return preset.framework
 }
 
-// DefaultConfig used to create a working yolov3 net out of the box.
+// applyNetConfig fills the input size and output layers using given Darknet net config and verifies
+// that the input channels, output layers and amount of class names match the net config.
+// The output layers of an explicitly chosen model kind are verified against the net config.
+func (c *Config) applyNetConfig(netConfig *darknet.Config, classNames int) error {
+	preset, err := c.ModelKind.preset()
+	if err != nil {
+		return err
+	}
+	outputLayers := netConfig.OutputLayerNames()
+	if len(preset.outputLayers) > 0 && strings.Join(preset.outputLayers, ",") != strings.Join(outputLayers, ",") {
+		return fmt.Errorf("net config does not match model kind %s: expected output layers %v, got %v", c.ModelKind, preset.outputLayers, outputLayers)
+	}
+	for _, layer := range c.OutputLayers {
+		if !slices.Contains(outputLayers, layer) {
+			return fmt.Errorf("output layer %s not found in net config", layer)
+		}
+	}
+	if len(c.OutputLayers) == 0 {
+		c.OutputLayers = outputLayers
+	}
+	// Without [yolo] sections, such as for [region] layers, the classes are verified on the output of the net
+	if len(netConfig.YoloLayers) > 0 && classNames != netConfig.Classes() {
+		return fmt.Errorf("net config has %d classes, but %d class names are provided", netConfig.Classes(), classNames)
+	}
+	// Frames are passed to the net as 3 channel BGR images
+	if netConfig.Channels != 0 && netConfig.Channels != 3 {
+		return fmt.Errorf("net config expects %d input channels, but frames have 3 channels", netConfig.Channels)
+	}
+	if c.InputWidth == 0 {
+		c.InputWidth = netConfig.Width
+	}
+	if c.InputHeight == 0 {
+		c.InputHeight = netConfig.Height
+	}
+	return nil
+}
+
+// DefaultConfig used to create a working yolov3 net out of the box. It leaves the model kind custom,
+// such that the topology is taken from the net config or the net itself.
 func DefaultConfig() Config {
+	return DefaultConfigForModel(ModelCustom)
+}
+
+// DefaultConfigForModel used to create a working net out of the box for given model kind.
+// The input size is left empty, such that it is taken from the net config or else the model kind.
+func DefaultConfigForModel(kind ModelKind) Config {
 	return Config{
-		InputWidth:          DefaultInputWidth,
-		InputHeight:         DefaultInputHeight,
+		ModelKind:           kind,
 		ConfidenceThreshold: DefaultConfThreshold,
 		NMSThreshold:        DefaultNMSThreshold,
 		NetTargetType:       gocv.NetTargetCPU,
 		NetBackendType:      gocv.NetBackendDefault,
 		NewNet:              initializeNet,
+		NewNetFromBytes:     initializeNetFromBytes,
 	}
 }
 
 // ObjectDetection represents information of an object detected by the neural net.
 type ObjectDetection struct {
-	ClassID     int
-	ClassName   string
+	ClassID   int
+	ClassName string
+	// BoundingBox is the bounding box in pixels of the frame, rounded to whole pixels
 	BoundingBox image.Rectangle
-	Confidence  float32
+	// Box is the bounding box in pixels of the frame with sub-pixel precision
+	Box Box
+	// NormalisedBox is the bounding box relative to the size of the frame
+	NormalisedBox Box
+	// Confidence is the score of the class, which includes the objectness for models with an objectness score
+	Confidence float32
+	// Objectness is the confidence that the box contains any object, one for models without an objectness score
+	Objectness float32
+	// TopClasses are the classes with the highest scores in descending order, if enabled in the config
+	TopClasses []ClassScore
+	// Scores are the scores of all classes, if enabled in the config
+	Scores []float32
+
+	// DisplayName, Alias, Group & Color contain the metadata of the class as provided by the label file
+	DisplayName string
+	Alias       string
+	Group       string
+	Color       color.RGBA
 }
 
 // Net the yolov3 net.
 type Net interface {
 	Close() error
 	GetDetections(gocv.Mat) ([]ObjectDetection, error)
-	GetDetectionsWithFilter(gocv.Mat, map[string]bool) ([]ObjectDetection, error)
+	GetDetectionsWithFilter(gocv.Mat, Filter) ([]ObjectDetection, error)
+	GetDetectionsContext(context.Context, gocv.Mat) ([]ObjectDetection, error)
+	GetDetectionsWithFilterContext(context.Context, gocv.Mat, Filter) ([]ObjectDetection, error)
+	GetDetectionsBatch([]gocv.Mat) ([][]ObjectDetection, error)
+	GetDetectionsBatchContext(context.Context, []gocv.Mat) ([][]ObjectDetection, error)
 }
 
 // yoloNet the net implementation.
 type yoloNet struct {
-	net       ml.NeuralNet
-	cocoNames []string
+	net          ml.NeuralNet
+	labels       []Label
+	inputName    string
+	outputLayers []string
+	decoder      OutputDecoder
+	resizeMode   ResizeMode
+	clipBoxes    bool
+	filter       Filter
+	topClasses   int
+	keepScores   bool
+	multiLabel   bool
 
 	DefaultInputWidth   int
 	DefaultInputHeight  int
 	confidenceThreshold float32
-	DefaultNMSThreshold float32
+	// classConfidenceThresholds overrides the confidence threshold per class id
+	classConfidenceThresholds map[int]float32
+	DefaultNMSThreshold       float32
+	nmsStrategy               NMSStrategy
+	softNMSSigma              float32
 }
 
 // NewNet creates new yolo net for given weight path, config and coconames list.
+// Besides a plain coconames file, a JSON or YAML label file can be provided, see LoadLabels.
 func NewNet(weightsPath, configPath, cocoNamePath string) (Net, error) {
 	return NewNetWithConfig(weightsPath, configPath, cocoNamePath, DefaultConfig())
 }
 
 // NewNetWithConfig creates new yolo net with given config.
+// The config path can be left empty for models which contain their own topology, such as ONNX models.
+// Unless configured, the framework of the model is determined by the extension of the weights path.
 func NewNetWithConfig(weightsPath, configPath, cocoNamePath string, config Config) (Net, error) {
 	if _, err := os.Stat(weightsPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("path to net weights not found")
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	if _, err := os.Stat(configPath); configPath != "" && os.IsNotExist(err) {
 		return nil, fmt.Errorf("path to net config not found")
 	}
 
-	cocoNames, err := getCocoNames(cocoNamePath)
+	var netConfig []byte
+	var err error
+	if configPath != "" {
+		netConfig, err = os.ReadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cocoNames, err := os.ReadFile(cocoNamePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Framework == "" {
+		config.Framework = frameworkFromPath(weightsPath)
+	}
+
+	return createNet(netConfig, cocoNames, filepath.Ext(cocoNamePath), config, func(config Config) (ml.NeuralNet, error) {
+		return config.NewNet(weightsPath, configPath), nil
+	})
+}
+
+// frameworkFromPath determines the framework of a model by the extension of its weights file,
+// as done by gocv.ReadNet. An empty framework is returned for unknown extensions.
+func frameworkFromPath(weightsPath string) string {
+	switch strings.ToLower(filepath.Ext(weightsPath)) {
+	case ".weights":
+		return "darknet"
+	case ".onnx":
+		return "onnx"
+	case ".caffemodel":
+		return "caffe"
+	case ".pb":
+		return "tensorflow"
+	case ".t7", ".net":
+		return "torch"
+	case ".bin":
+		return "dldt"
+	default:
+		return ""
+	}
+}
+
+// createNet creates new yolo net from the contents of the net config and coconames, loading the neural net
+// once the config is validated. The extension of the coconames file determines the format of the labels,
+// when left empty the format is determined by the contents.
+func createNet(netConfig, cocoNames []byte, cocoNamesExt string, config Config, load func(config Config) (ml.NeuralNet, error)) (Net, error) {
+	labels, err := parseLabels(cocoNames, cocoNamesExt)
 	if err != nil {
 		return nil, err
 	}
 
-	config.validate()
+	var parsedNetConfig *darknet.Config
+	if len(netConfig) > 0 && config.framework() == "darknet" {
+		parsedNetConfig, err = darknet.Parse(bytes.NewReader(netConfig))
+		if err != nil {
+			return nil, err
+		}
+		err = config.applyNetConfig(parsedNetConfig, len(labels))
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	net := config.NewNet(weightsPath, configPath)
+	err = config.validate()
+	if err != nil {
+		return nil, err
+	}
 
-	err = setNetTargetTypes(net, config)
+	net, err := load(config)
 	if err != nil {
 		return nil, err
 	}
 
-	return &yoloNet{
+	return newYoloNet(net, labels, parsedNetConfig, config)
+}
+
+// initializeNet default method for creating neural network, leveraging gocv.
+func initializeNet(weightsPath, configPath string) ml.NeuralNet {
+	net := gocv.ReadNet(weightsPath, configPath)
+	return &net
+}
+
+// newYoloNet creates the yolo net for an initialised neural net and validated config.
+// Without the classes of a Darknet net config, the amount of class names is verified against the output of the net.
+func newYoloNet(net ml.NeuralNet, labels []Label, netConfig *darknet.Config, config Config) (Net, error) {
+	err := setNetTargetTypes(net, config)
+	if err != nil {
+		return nil, errors.Join(err, net.Close())
+	}
+
+	outputLayers := config.OutputLayers
+	if len(outputLayers) == 0 {
+		outputLayers, err = getOutputLayerNames(net)
+		if err != nil {
+			return nil, errors.Join(err, net.Close())
+		}
+	}
+
+	classConfidenceThresholds, err := resolveClassConfidenceThresholds(config.ClassConfidenceThresholds, labels)
+	if err != nil {
+		return nil, errors.Join(err, net.Close())
+	}
+
+	y := &yoloNet{
 		net:                 net,
-		cocoNames:           cocoNames,
+		labels:              labels,
+		inputName:           config.InputName,
+		outputLayers:        outputLayers,
+		decoder:             config.OutputDecoder,
+		resizeMode:          config.ResizeMode,
+		clipBoxes:           config.ClipBoxes,
+		filter:              config.Filter,
+		topClasses:          config.TopClasses,
+		keepScores:          config.KeepScores,
+		multiLabel:          config.MultiLabel,
 		DefaultInputWidth:   config.InputWidth,
 		DefaultInputHeight:  config.InputHeight,
 		confidenceThreshold: config.ConfidenceThreshold,
 		DefaultNMSThreshold: config.NMSThreshold,
-	}, nil
+
+		classConfidenceThresholds: classConfidenceThresholds,
+		nmsStrategy:               config.NMSStrategy,
+		softNMSSigma:              config.SoftNMSSigma,
+	}
+	if netConfig == nil || len(netConfig.YoloLayers) == 0 {
+		err = y.verifyClassNames()
+		if err != nil {
+			return nil, errors.Join(err, net.Close())
+		}
+	}
+	return y, nil
 }
 
-// initializeNet default method for creating neural network, leveraging gocv.
-func initializeNet(weightsPath, configPath string) ml.NeuralNet {
-	net := gocv.ReadNet(weightsPath, configPath)
-	return &net
+// verifyClassNames verifies that the amount of class names matches the amount of classes
+// in the output of the net, by forwarding an empty frame.
+func (y *yoloNet) verifyClassNames() error {
+	outputs := y.forwardEmptyFrame()
+	for i := 0; i < len(outputs); i++ {
+		// nolint: errcheck
+		defer outputs[i].Close()
+	}
+
+	classes, err := y.decoder.Classes(outputs)
+	if err != nil {
+		return err
+	}
+	if classes != len(y.labels) {
+		return fmt.Errorf("net outputs %d classes, but %d class names are provided", classes, len(y.labels))
+	}
+	return nil
+}
+
+// warmUp forwards an empty frame, such that the first detection is not slowed down by
+// the initialisation of the backend.
+func (y *yoloNet) warmUp() error {
+	var err error
+	for _, output := range y.forwardEmptyFrame() {
+		err = errors.Join(err, output.Close())
+	}
+	return err
+}
+
+// forwardEmptyFrame forwards an empty frame of the input size through the net, the caller is
+// responsible for closing the outputs.
+func (y *yoloNet) forwardEmptyFrame() []gocv.Mat {
+	frame := gocv.NewMatWithSize(y.DefaultInputHeight, y.DefaultInputWidth, gocv.MatTypeCV8UC3)
+	// nolint: errcheck
+	defer frame.Close()
+	// The background context is never done
+	outputs, _ := y.forward(context.Background(), frame)
+	return outputs
 }
 
 func setNetTargetTypes(net ml.NeuralNet, config Config) error {
@@ -158,6 +455,23 @@ func setNetTargetTypes(net ml.NeuralNet, config Config) error {
 	return nil
 }
 
+// getOutputLayerNames retrieves the names of the unconnected output layers of the net.
+func getOutputLayerNames(net ml.NeuralNet) ([]string, error) {
+	layerNames := net.GetLayerNames()
+	outputLayers := []string{}
+	for _, id := range net.GetUnconnectedOutLayers() {
+		// Layer ids are 1-based, as id 0 is reserved for the input layer
+		if id < 1 || id > len(layerNames) {
+			return nil, fmt.Errorf("output layer id %d out of range", id)
+		}
+		outputLayers = append(outputLayers, layerNames[id-1])
+	}
+	if len(outputLayers) == 0 {
+		return nil, fmt.Errorf("unable to determine output layers of the net")
+	}
+	return outputLayers, nil
+}
+
 // Close closes the net.
 func (y *yoloNet) Close() error {
 	return y.net.Close()
@@ -165,24 +479,41 @@ func (y *yoloNet) Close() error {
 
 // GetDetections retrieve predicted detections from given matrix.
 func (y *yoloNet) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
-	return y.GetDetectionsWithFilter(frame, make(map[string]bool))
+	return y.GetDetectionsWithFilter(frame, y.filter)
 }
 
-// GetDetectionsWithFilter allows you to detect objects, but filter out a given list of coco name ids.
-func (y *yoloNet) GetDetectionsWithFilter(frame gocv.Mat, classIDsFilter map[string]bool) ([]ObjectDetection, error) {
-	fl := []string{"yolo_82", "yolo_94", "yolo_106"}
-	blob := gocv.BlobFromImage(frame, 1.0/255.0, image.Pt(y.DefaultInputWidth, y.DefaultInputHeight), gocv.NewScalar(0, 0, 0, 0), true, false)
-	// nolint: errcheck
-	defer blob.Close()
-	y.net.SetInput(blob, "data")
+// GetDetectionsWithFilter allows you to detect objects, reporting the classes allowed by given filter instead of the filter of the net.
+func (y *yoloNet) GetDetectionsWithFilter(frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	return y.GetDetectionsWithFilterContext(context.Background(), frame, filter)
+}
+
+// GetDetectionsContext retrieve predicted detections from given matrix, unless the context is done.
+func (y *yoloNet) GetDetectionsContext(ctx context.Context, frame gocv.Mat) ([]ObjectDetection, error) {
+	return y.GetDetectionsWithFilterContext(ctx, frame, y.filter)
+}
 
-	outputs := y.net.ForwardLayers(fl)
+// GetDetectionsWithFilterContext allows you to detect objects, reporting the classes allowed by given filter instead of the filter of the net.
+// The context is checked before preprocessing, forwarding and post-processing the frame, the forward itself
+// can not be interrupted.
+func (y *yoloNet) GetDetectionsWithFilterContext(ctx context.Context, frame gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	outputs, err := y.forward(ctx, frame)
+	if err != nil {
+		return nil, err
+	}
 	for i := 0; i < len(outputs); i++ {
 		// nolint: errcheck
 		defer outputs[i].Close()
 	}
 
-	detections, err := y.processOutputs(frame, outputs, classIDsFilter)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	detections, err := y.processOutputs(frame, outputs, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -190,73 +521,133 @@ func (y *yoloNet) GetDetectionsWithFilter(frame gocv.Mat, classIDsFilter map[str
 	return detections, nil
 }
 
+// forward runs the frame through the net, the caller is responsible for closing the outputs.
+func (y *yoloNet) forward(ctx context.Context, frame gocv.Mat) ([]gocv.Mat, error) {
+	blob := blobFromFrames([]gocv.Mat{frame}, image.Pt(y.DefaultInputWidth, y.DefaultInputHeight), y.resizeMode)
+	// nolint: errcheck
+	defer blob.Close()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	y.net.SetInput(blob, y.inputName)
+
+	return y.net.ForwardLayers(y.outputLayers), nil
+}
+
 // processOutputs process detected rows in the outputs.
-func (y *yoloNet) processOutputs(frame gocv.Mat, outputs []gocv.Mat, filter map[string]bool) ([]ObjectDetection, error) {
+func (y *yoloNet) processOutputs(frame gocv.Mat, outputs []gocv.Mat, filter Filter) ([]ObjectDetection, error) {
+	decoder := y.decoder
+	if decoder == nil {
+		decoder = DarknetDecoder{}
+	}
 	detections := []ObjectDetection{}
-	bboxes := []image.Rectangle{}
-	confidences := []float32{}
-	for i := 0; i < len(outputs); i++ {
-		data, err := outputs[i].DataPtrFloat32()
-		if err != nil {
-			return nil, err
-		}
-		for j := 0; j < outputs[i].Total(); j += outputs[i].Cols() {
-			row := data[j : j+outputs[i].Cols()]
-			scores := row[5:]
-			classID, confidence := getClassIDAndConfidence(scores)
+	inputSize := image.Pt(y.DefaultInputWidth, y.DefaultInputHeight)
+	frameSize := image.Pt(frame.Cols(), frame.Rows())
+	transform := newBoxTransform(frameSize, inputSize, y.resizeMode)
+	var classErr error
+	err := decoder.Decode(outputs, inputSize, func(candidate Candidate) {
+		scores := candidate.Scores
+		for _, classID := range y.candidateClasses(scores) {
+			if classID >= len(y.labels) {
+				classErr = fmt.Errorf("detected class id %d, but only %d class names are provided", classID, len(y.labels))
+				return
+			}
+			label := y.labels[classID]
 			if y.isFiltered(classID, filter) {
 				continue
 			}
-			if confidence > y.confidenceThreshold {
-				confidences = append(confidences, confidence)
-
-				boundingBox := calculateBoundingBox(frame, row)
-				bboxes = append(bboxes, boundingBox)
-				detections = append(detections, ObjectDetection{
+			confidence := scores[classID]
+			if confidence > y.classConfidenceThreshold(classID) {
+				detection := ObjectDetection{
 					ClassID:     classID,
-					ClassName:   y.cocoNames[classID],
-					BoundingBox: boundingBox,
+					ClassName:   label.Name,
 					Confidence:  confidence,
-				})
+					Objectness:  candidate.Objectness,
+					TopClasses:  y.classScores(scores),
+					Scores:      y.scoreVector(scores),
+					DisplayName: label.DisplayName,
+					Alias:       label.Alias,
+					Group:       label.Group,
+					Color:       label.Color,
+				}
+				frameBox := transform.box(candidate.Box[:])
+				if y.clipBoxes {
+					frameBox = frameBox.Clip(frameSize)
+				}
+				detection.setBox(frameBox, frameSize)
+				detections = append(detections, detection)
 			}
 		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if classErr != nil {
+		return nil, classErr
 	}
-	if len(bboxes) == 0 {
+	if len(detections) == 0 {
 		return detections, nil
 	}
 
-	indices := gocv.NMSBoxes(bboxes, confidences, y.confidenceThreshold, y.DefaultNMSThreshold)
-	result := []ObjectDetection{}
-	for i, indice := range indices {
-		// If we encounter value 0 skip the detection
-		// except for the first indice
-		if i != 0 && indice == 0 {
-			continue
+	return y.nonMaxSuppression(detections), nil
+}
+
+// isFiltered determines whether detections of the class are left out, either because the
+// class is unknown, disabled in the label file or not allowed by the filter.
+func (y *yoloNet) isFiltered(classID int, filter Filter) bool {
+	if classID < 0 || classID >= len(y.labels) {
+		return true
+	}
+	label := y.labels[classID]
+	return !label.Enabled || !filter.allows(classID, label)
+}
+
+// classConfidenceThreshold retrieves the confidence threshold of the class, falling back to the confidence threshold of the net.
+func (y *yoloNet) classConfidenceThreshold(classID int) float32 {
+	if threshold, ok := y.classConfidenceThresholds[classID]; ok {
+		return threshold
+	}
+	return y.confidenceThreshold
+}
+
+// minConfidenceThreshold retrieves the lowest confidence threshold of any class. As detections already
+// exceed the threshold of their class, it suffices for suppressing boxes of multiple classes at once.
+func (y *yoloNet) minConfidenceThreshold() float32 {
+	threshold := y.confidenceThreshold
+	for _, classThreshold := range y.classConfidenceThresholds {
+		if classThreshold < threshold {
+			threshold = classThreshold
 		}
-		result = append(result, detections[indice])
 	}
-	return result, nil
+	return threshold
 }
 
-func (y *yoloNet) isFiltered(classID int, classIDs map[string]bool) bool {
-	if classIDs == nil {
-		return false
+// resolveClassConfidenceThresholds resolves the class names or ids of the thresholds into class ids.
+func resolveClassConfidenceThresholds(thresholds map[string]float32, labels []Label) (map[int]float32, error) {
+	result := map[int]float32{}
+	for class, threshold := range thresholds {
+		classID, err := resolveClassID(class, labels)
+		if err != nil {
+			return nil, fmt.Errorf("invalid class confidence threshold: %w", err)
+		}
+		result[classID] = threshold
 	}
-	return classIDs[y.cocoNames[classID]]
+	return result, nil
 }
 
-// calculateBoundingBox calculate the bounding box of the detected object.
-func calculateBoundingBox(frame gocv.Mat, row []float32) image.Rectangle {
-	if len(row) < 4 {
-		return image.Rect(0, 0, 0, 0)
+// resolveClassID resolves a class name, or class id, into the class id.
+func resolveClassID(class string, labels []Label) (int, error) {
+	for i, label := range labels {
+		if label.Name == class {
+			return i, nil
+		}
+	}
+	classID, err := strconv.Atoi(class)
+	if err != nil || classID < 0 || classID >= len(labels) {
+		return 0, fmt.Errorf("unknown class %q", class)
 	}
-	centerX := int(row[0] * float32(frame.Cols()))
-	centerY := int(row[1] * float32(frame.Rows()))
-	width := int(row[2] * float32(frame.Cols()))
-	height := int(row[3] * float32(frame.Rows()))
-	left := (centerX - width/2)
-	top := (centerY - height/2)
-	return image.Rect(left, top, left+width, top+height)
+	return classID, nil
 }
 
 // getClassID retrieve class id from given row.
@@ -272,24 +663,37 @@ func getClassIDAndConfidence(x []float32) (int, float32) {
 	return res, max
 }
 
-// getCocoNames read coconames from given path.
-func getCocoNames(path string) ([]string, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// parseCocoNames parses the content of a coconames file, containing a class name per line.
+// Blank lines and lines starting with a '#' are ignored.
+func parseCocoNames(content []byte) []string {
+	cocoNames := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cocoNames = append(cocoNames, line)
 	}
-	return strings.Split(string(content), "\n"), nil
+	return cocoNames
 }
 
 // DrawDetections draws a given list of object detections on a gocv Matrix.
+// Detections are drawn using the display name and color of their label, if set.
 func DrawDetections(frame *gocv.Mat, detections []ObjectDetection) {
 	for i := 0; i < len(detections); i++ {
 		detection := detections[i]
-		text := fmt.Sprintf("%s:%.2f", detection.ClassName, detection.Confidence)
+		name := detection.ClassName
+		if detection.DisplayName != "" {
+			name = detection.DisplayName
+		}
+		text := fmt.Sprintf("%s:%.2f", name, detection.Confidence)
 
 		// Create bounding box of object
-		blue := color.RGBA{0, 0, 255, 0}
-		gocv.Rectangle(frame, detection.BoundingBox, blue, 3)
+		boxColor := detection.Color
+		if boxColor == (color.RGBA{}) {
+			boxColor = color.RGBA{0, 0, 255, 0}
+		}
+		gocv.Rectangle(frame, detection.BoundingBox, boxColor, 3)
 
 		// Add text background
 		black := color.RGBA{0, 0, 0, 0}
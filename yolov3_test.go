@@ -1,11 +1,13 @@
 package yolov3
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
@@ -26,6 +28,12 @@ func TestYoloTestSuite(t *testing.T) {
 
 func (s *YoloTestSuite) TestCorrectImplementation() {
 	var _ Net = &yoloNet{}
+	var _ Net = &ReloadableNet{}
+	var _ Net = &NetPool{}
+	var _ Net = &TiledNet{}
+	var _ Net = &AugmentedNet{}
+	var _ Net = &RegionNet{}
+	var _ Net = &EnsembleNet{}
 }
 
 func (s *YoloTestSuite) TestNewDefaultNetCorrectCreation() {
@@ -34,7 +42,8 @@ func (s *YoloTestSuite) TestNewDefaultNetCorrectCreation() {
 	yoloNet := net.(*yoloNet)
 
 	s.NotNil(yoloNet.net)
-	s.Equal(81, len(yoloNet.cocoNames))
+	s.Equal(80, len(yoloNet.labels))
+	s.Equal([]string{"yolo_82", "yolo_94", "yolo_106"}, yoloNet.outputLayers)
 	s.Equal(DefaultInputWidth, yoloNet.DefaultInputWidth)
 	s.Equal(DefaultInputHeight, yoloNet.DefaultInputHeight)
 	s.Equal(DefaultConfThreshold, yoloNet.confidenceThreshold)
@@ -49,7 +58,7 @@ func (s *YoloTestSuite) TestNewCustomConfig_MissingNewNetFunc_CorrectCreation()
 	yoloNet := net.(*yoloNet)
 
 	s.NotNil(yoloNet.net)
-	s.Equal(81, len(yoloNet.cocoNames))
+	s.Equal(80, len(yoloNet.labels))
 	s.Equal(DefaultInputWidth, yoloNet.DefaultInputWidth)
 	s.Equal(DefaultInputHeight, yoloNet.DefaultInputHeight)
 	s.Equal(float32(0), yoloNet.confidenceThreshold)
@@ -97,6 +106,8 @@ func (s *YoloTestSuite) TestUnableTocCreateNewNet() {
 				controller := gomock.NewController(s.T())
 				neuralNetMock := mocks.NewMockNeuralNet(controller)
 				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(fmt.Errorf("very broken")).Times(1)
+				// The net is closed when it can not be used
+				neuralNetMock.EXPECT().Close().Return(nil).Times(1)
 				return neuralNetMock
 			},
 			Error: fmt.Errorf("very broken"),
@@ -111,10 +122,66 @@ func (s *YoloTestSuite) TestUnableTocCreateNewNet() {
 				neuralNetMock := mocks.NewMockNeuralNet(controller)
 				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
 				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(fmt.Errorf("very broken")).Times(1)
+				neuralNetMock.EXPECT().Close().Return(nil).Times(1)
 				return neuralNetMock
 			},
 			Error: fmt.Errorf("very broken"),
 		},
+		{
+			Name:         "Unable to determine output layers",
+			WeightsPath:  "data/yolov3/yolov3.weights",
+			CocoNamePath: "data/yolov3/coco.names",
+			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().GetLayerNames().Return([]string{"conv_0"}).Times(1)
+				neuralNetMock.EXPECT().GetUnconnectedOutLayers().Return([]int{}).Times(1)
+				neuralNetMock.EXPECT().Close().Return(nil).Times(1)
+				return neuralNetMock
+			},
+			Error: fmt.Errorf("unable to determine output layers of the net"),
+		},
+		{
+			Name:         "Output layer id out of range",
+			WeightsPath:  "data/yolov3/yolov3.weights",
+			CocoNamePath: "data/yolov3/coco.names",
+			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().GetLayerNames().Return([]string{"conv_0"}).Times(1)
+				neuralNetMock.EXPECT().GetUnconnectedOutLayers().Return([]int{2}).Times(1)
+				neuralNetMock.EXPECT().Close().Return(nil).Times(1)
+				return neuralNetMock
+			},
+			Error: fmt.Errorf("output layer id 2 out of range"),
+		},
+		{
+			Name:         "Net config does not match model kind",
+			WeightsPath:  "data/yolov3/yolov3.weights",
+			ConfigPath:   "data/yolov3/yolov3.cfg",
+			CocoNamePath: "data/yolov3/coco.names",
+			Config:       Config{ModelKind: ModelYoloV3Tiny},
+			Error:        fmt.Errorf("net config does not match model kind yolov3-tiny: expected output layers [yolo_16 yolo_23], got [yolo_82 yolo_94 yolo_106]"),
+		},
+		{
+			Name:         "Output layer not found in net config",
+			WeightsPath:  "data/yolov3/yolov3.weights",
+			ConfigPath:   "data/yolov3/yolov3.cfg",
+			CocoNamePath: "data/yolov3/coco.names",
+			Config:       Config{OutputLayers: []string{"yolo_16"}},
+			Error:        fmt.Errorf("output layer yolo_16 not found in net config"),
+		},
+		{
+			Name:         "Not enough class names for net config",
+			WeightsPath:  "data/yolov3/yolov3.weights",
+			ConfigPath:   "data/yolov3/yolov3.cfg",
+			CocoNamePath: "testdata/laptop_coffee.names",
+			Error:        fmt.Errorf("net config has 80 classes, but 2 class names are provided"),
+		},
 	}
 
 	for _, test := range tests {
@@ -125,12 +192,101 @@ func (s *YoloTestSuite) TestUnableTocCreateNewNet() {
 			_, err := NewNetWithConfig(test.WeightsPath, test.ConfigPath, test.CocoNamePath, test.Config)
 			s.Error(err)
 			if test.Error != nil {
-				s.Equal(test.Error, err)
+				s.EqualError(err, test.Error.Error())
 			}
 		})
 	}
 }
 
+func (s *YoloTestSuite) TestNewNetOutputLayers() {
+	tests := []struct {
+		Name                 string
+		ConfigPath           string
+		Config               Config
+		SetupNeuralNetMock   func() *mocks.MockNeuralNet
+		ExpectedInputName    string
+		ExpectedOutputLayers []string
+	}{
+		{
+			Name:       "Output layers of the net config",
+			ConfigPath: "testdata/yolov3-tiny.cfg",
+			Config:     DefaultConfig(),
+			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+				return neuralNetMock
+			},
+			ExpectedOutputLayers: []string{"yolo_16", "yolo_23"},
+		},
+		{
+			Name:   "Discover unconnected output layers without net config",
+			Config: DefaultConfig(),
+			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().GetLayerNames().Return([]string{"conv_0", "yolo_1", "conv_2", "yolo_3"}).Times(1)
+				neuralNetMock.EXPECT().GetUnconnectedOutLayers().Return([]int{2, 4}).Times(1)
+				neuralNetMock.EXPECT().SetInput(gomock.Any(), "").Times(1)
+				neuralNetMock.EXPECT().ForwardLayers([]string{"yolo_1", "yolo_3"}).Return([]gocv.Mat{cocoOutput(), cocoOutput()}).Times(1)
+				return neuralNetMock
+			},
+			ExpectedOutputLayers: []string{"yolo_1", "yolo_3"},
+		},
+		{
+			Name:       "Overridden input name and output layers",
+			ConfigPath: "data/yolov3/yolov3.cfg",
+			Config: Config{
+				InputName:    "images",
+				OutputLayers: []string{"yolo_94"},
+			},
+			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+				return neuralNetMock
+			},
+			ExpectedInputName:    "images",
+			ExpectedOutputLayers: []string{"yolo_94"},
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			test.Config.NewNet = func(string, string) ml.NeuralNet {
+				return test.SetupNeuralNetMock()
+			}
+			net, err := NewNetWithConfig("data/yolov3/yolov3.weights", test.ConfigPath, "data/yolov3/coco.names", test.Config)
+			s.Require().NoError(err)
+			yoloNet := net.(*yoloNet)
+			s.Equal(test.ExpectedInputName, yoloNet.inputName)
+			s.Equal(test.ExpectedOutputLayers, yoloNet.outputLayers)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestNewNetInputSizeFromNetConfig() {
+	config := Config{
+		NewNet: func(string, string) ml.NeuralNet {
+			controller := gomock.NewController(s.T())
+			neuralNetMock := mocks.NewMockNeuralNet(controller)
+			neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+			neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(nil).Times(1)
+			return neuralNetMock
+		},
+	}
+	net, err := NewNetWithConfig("data/yolov3/yolov3.weights", "testdata/yolov4.cfg", "data/yolov3/coco.names", config)
+	s.Require().NoError(err)
+	yoloNet := net.(*yoloNet)
+	s.Equal(608, yoloNet.DefaultInputWidth)
+	s.Equal(608, yoloNet.DefaultInputHeight)
+	s.Equal([]string{"yolo_139", "yolo_150", "yolo_161"}, yoloNet.outputLayers)
+}
+
 func (s *YoloTestSuite) TestClassIDAndConfidence() {
 	tests := []struct {
 		Name              string
@@ -172,30 +328,40 @@ func (s *YoloTestSuite) TestClassIDAndConfidence() {
 	}
 }
 
-func (s *YoloTestSuite) TestCalculateBoundingBox() {
+func (s *YoloTestSuite) TestParseCocoNames() {
 	tests := []struct {
-		Name         string
-		InputFrame   gocv.Mat
-		InputRow     []float32
-		ExpectedRect image.Rectangle
+		Name     string
+		Input    string
+		Expected []string
 	}{
 		{
-			Name:         "normal bounding box calculation",
-			InputFrame:   gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-			InputRow:     []float32{1, 1, 1, 1},
-			ExpectedRect: image.Rect(1, 1, 3, 3),
+			Name:     "trailing newline",
+			Input:    "laptop\ncoffee\n",
+			Expected: []string{"laptop", "coffee"},
+		},
+		{
+			Name:     "CRLF line endings",
+			Input:    "laptop\r\ncoffee\r\n",
+			Expected: []string{"laptop", "coffee"},
+		},
+		{
+			Name:     "blank lines and comments",
+			Input:    "# office classes\n\nlaptop\n  \n# drinks\ncoffee",
+			Expected: []string{"laptop", "coffee"},
+		},
+		{
+			Name:     "names containing spaces",
+			Input:    " cell phone \nteddy bear",
+			Expected: []string{"cell phone", "teddy bear"},
 		},
 		{
-			Name:         "unexpected row",
-			InputFrame:   gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-			InputRow:     []float32{1, 1, 1},
-			ExpectedRect: image.Rect(0, 0, 0, 0),
+			Name:     "empty",
+			Expected: []string{},
 		},
 	}
 	for _, test := range tests {
 		s.Run(test.Name, func() {
-			rect := calculateBoundingBox(test.InputFrame, test.InputRow)
-			s.Equal(test.ExpectedRect, rect)
+			s.Equal(test.Expected, parseCocoNames([]byte(test.Input)))
 		})
 	}
 }
@@ -204,7 +370,7 @@ func (s *YoloTestSuite) TestIsFiltered() {
 	tests := []struct {
 		Name     string
 		ClassID  int
-		ClassIDs map[string]bool
+		Filter   Filter
 		Expected bool
 	}{
 		{
@@ -214,22 +380,82 @@ func (s *YoloTestSuite) TestIsFiltered() {
 		{
 			Name:     "is filtered",
 			ClassID:  1,
-			ClassIDs: map[string]bool{"coffee": true},
+			Filter:   Filter{ExcludeNames: []string{"coffee"}},
 			Expected: true,
 		},
 		{
 			Name:     "is not filtered",
 			ClassID:  0,
-			ClassIDs: map[string]bool{"coffee": true},
+			Filter:   Filter{ExcludeNames: []string{"coffee"}},
+			Expected: false,
+		},
+		{
+			Name:     "excluded by id",
+			ClassID:  1,
+			Filter:   Filter{ExcludeIDs: []int{1}},
+			Expected: true,
+		},
+		{
+			Name:     "excluded by group",
+			ClassID:  1,
+			Filter:   Filter{ExcludeGroups: []string{"drinks"}},
+			Expected: true,
+		},
+		{
+			Name:     "included by name",
+			ClassID:  1,
+			Filter:   Filter{IncludeNames: []string{"coffee"}},
+			Expected: false,
+		},
+		{
+			Name:     "not included",
+			ClassID:  0,
+			Filter:   Filter{IncludeNames: []string{"coffee"}},
+			Expected: true,
+		},
+		{
+			Name:     "included by id",
+			ClassID:  0,
+			Filter:   Filter{IncludeNames: []string{"coffee"}, IncludeIDs: []int{0}},
+			Expected: false,
+		},
+		{
+			Name:     "included by group",
+			ClassID:  1,
+			Filter:   Filter{IncludeGroups: []string{"drinks"}},
 			Expected: false,
 		},
+		{
+			Name:     "class without group not included",
+			ClassID:  0,
+			Filter:   Filter{IncludeGroups: []string{""}},
+			Expected: true,
+		},
+		{
+			Name:     "exclude takes precedence",
+			ClassID:  1,
+			Filter:   Filter{IncludeGroups: []string{"drinks"}, ExcludeIDs: []int{1}},
+			Expected: true,
+		},
+		{
+			Name:     "unknown class",
+			ClassID:  2,
+			Expected: true,
+		},
+		{
+			Name:     "negative class",
+			ClassID:  -1,
+			Expected: true,
+		},
 	}
 	for _, test := range tests {
 		s.Run(test.Name, func() {
+			labels := labelsFromNames([]string{"laptop", "coffee"})
+			labels[1].Group = "drinks"
 			y := &yoloNet{
-				cocoNames: []string{"laptop", "coffee"},
+				labels: labels,
 			}
-			s.Equal(test.Expected, y.isFiltered(test.ClassID, test.ClassIDs))
+			s.Equal(test.Expected, y.isFiltered(test.ClassID, test.Filter))
 		})
 	}
 }
@@ -239,8 +465,13 @@ func (s *YoloTestSuite) TestProcessOutputs() {
 		Name                      string
 		InputFrame                gocv.Mat
 		InputOutputs              []gocv.Mat
-		InputFilter               map[string]bool
+		InputFilter               Filter
 		InputConfidenceThreshHold float32
+		InputClassThreshHolds     map[int]float32
+		InputTopClasses           int
+		InputKeepScores           bool
+		InputMultiLabel           bool
+		InputClipBoxes            bool
 		Result                    []ObjectDetection
 		ExpectError               bool
 	}{
@@ -253,7 +484,6 @@ func (s *YoloTestSuite) TestProcessOutputs() {
 
 				return []gocv.Mat{laptopDetection, coffeeDetection}
 			}(),
-			InputFilter: map[string]bool{},
 			Result: []ObjectDetection{
 				{
 					ClassID:     0,
@@ -285,7 +515,7 @@ func (s *YoloTestSuite) TestProcessOutputs() {
 
 				return []gocv.Mat{coffeeDetection}
 			}(),
-			InputFilter: map[string]bool{"coffee": true},
+			InputFilter: Filter{ExcludeNames: []string{"coffee"}},
 			Result:      []ObjectDetection{},
 		},
 		{
@@ -297,9 +527,58 @@ func (s *YoloTestSuite) TestProcessOutputs() {
 				return []gocv.Mat{coffeeDetection}
 			}(),
 			InputConfidenceThreshHold: 999,
-			InputFilter:               map[string]bool{"coffee": true},
+			InputFilter:               Filter{ExcludeNames: []string{"coffee"}},
 			Result:                    []ObjectDetection{},
 		},
+		{
+			Name:       "Confidence not high enough for class",
+			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				laptopDetection := laptopDetection()
+				coffeeDetection := coffeeDetection()
+
+				return []gocv.Mat{laptopDetection, coffeeDetection}
+			}(),
+			InputClassThreshHolds: map[int]float32{1: 9.5},
+			Result: []ObjectDetection{
+				{
+					ClassID:     0,
+					Confidence:  9,
+					ClassName:   "laptop",
+					BoundingBox: image.Rect(1, 1, 3, 3),
+				},
+			},
+		},
+		{
+			Name:       "Class threshold below default threshold",
+			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				laptopDetection := laptopDetection()
+				coffeeDetection := coffeeDetection()
+
+				return []gocv.Mat{laptopDetection, coffeeDetection}
+			}(),
+			InputConfidenceThreshHold: 9.5,
+			InputClassThreshHolds:     map[int]float32{1: 8},
+			Result: []ObjectDetection{
+				{
+					ClassID:     1,
+					Confidence:  9,
+					ClassName:   "coffee",
+					BoundingBox: image.Rect(-1, 1, 1, 3),
+				},
+			},
+		},
+		{
+			Name:       "Class id without class name",
+			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				detection := gocv.NewMatWithSize(1, 10, gocv.MatTypeCV32F)
+				detection.SetFloatAt(0, 9, 9)
+				return []gocv.Mat{detection}
+			}(),
+			ExpectError: true,
+		},
 		{
 			Name:       "Filter overlapping frame",
 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
@@ -309,7 +588,6 @@ func (s *YoloTestSuite) TestProcessOutputs() {
 				coffeeDetection2.SetFloatAt(0, 6, 10)
 				return []gocv.Mat{coffeeDetection1, coffeeDetection2}
 			}(),
-			InputFilter: map[string]bool{},
 			Result: []ObjectDetection{
 				{
 					ClassID:     1,
@@ -319,12 +597,123 @@ func (s *YoloTestSuite) TestProcessOutputs() {
 				},
 			},
 		},
+		{
+			Name:       "Box clipped to the frame",
+			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{coffeeDetection()}
+			}(),
+			InputClipBoxes: true,
+			Result: []ObjectDetection{
+				{
+					ClassID:       1,
+					Confidence:    9,
+					ClassName:     "coffee",
+					BoundingBox:   image.Rect(0, 1, 1, 2),
+					Box:           Box{MinX: 0, MinY: 1, MaxX: 1, MaxY: 2},
+					NormalisedBox: Box{MinX: 0, MinY: 0.5, MaxX: 0.5, MaxY: 1},
+				},
+			},
+		},
+		{
+			Name:       "Sub-pixel box",
+			InputFrame: gocv.NewMatWithSize(4, 4, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				detection := laptopDetection()
+				detection.SetFloatAt(0, 0, 0.5)
+				detection.SetFloatAt(0, 1, 0.5)
+				detection.SetFloatAt(0, 2, 0.375)
+				detection.SetFloatAt(0, 3, 0.25)
+				return []gocv.Mat{detection}
+			}(),
+			Result: []ObjectDetection{
+				{
+					ClassID:       0,
+					Confidence:    9,
+					ClassName:     "laptop",
+					BoundingBox:   image.Rect(1, 2, 3, 3),
+					Box:           Box{MinX: 1.25, MinY: 1.5, MaxX: 2.75, MaxY: 2.5},
+					NormalisedBox: Box{MinX: 0.3125, MinY: 0.375, MaxX: 0.6875, MaxY: 0.625},
+				},
+			},
+		},
+		{
+			Name:       "Objectness, top classes and scores",
+			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{multiLabelDetection()}
+			}(),
+			InputTopClasses: 2,
+			InputKeepScores: true,
+			Result: []ObjectDetection{
+				{
+					ClassID:     0,
+					Confidence:  9,
+					Objectness:  0.8,
+					ClassName:   "laptop",
+					BoundingBox: image.Rect(1, 1, 3, 3),
+					TopClasses: []ClassScore{
+						{ClassID: 0, ClassName: "laptop", Score: 9},
+						{ClassID: 1, ClassName: "coffee", Score: 5},
+					},
+					Scores: []float32{9, 5, 0, 0, 0},
+				},
+			},
+		},
+		{
+			Name:       "Multi-label detections",
+			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{multiLabelDetection()}
+			}(),
+			InputMultiLabel: true,
+			Result: []ObjectDetection{
+				{
+					ClassID:     0,
+					Confidence:  9,
+					Objectness:  0.8,
+					ClassName:   "laptop",
+					BoundingBox: image.Rect(1, 1, 3, 3),
+				},
+				{
+					ClassID:     1,
+					Confidence:  5,
+					Objectness:  0.8,
+					ClassName:   "coffee",
+					BoundingBox: image.Rect(1, 1, 3, 3),
+				},
+			},
+		},
+		{
+			Name:       "Multi-label detections below class threshold",
+			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
+			InputOutputs: func() []gocv.Mat {
+				return []gocv.Mat{multiLabelDetection()}
+			}(),
+			InputClassThreshHolds: map[int]float32{1: 6},
+			InputMultiLabel:       true,
+			Result: []ObjectDetection{
+				{
+					ClassID:     0,
+					Confidence:  9,
+					Objectness:  0.8,
+					ClassName:   "laptop",
+					BoundingBox: image.Rect(1, 1, 3, 3),
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		s.Run(test.Name, func() {
 			y := &yoloNet{
-				cocoNames:           []string{"laptop", "coffee"},
+				labels:              labelsFromNames([]string{"laptop", "coffee"}),
 				confidenceThreshold: test.InputConfidenceThreshHold,
+
+				classConfidenceThresholds: test.InputClassThreshHolds,
+				topClasses:                test.InputTopClasses,
+				keepScores:                test.InputKeepScores,
+				multiLabel:                test.InputMultiLabel,
+				clipBoxes:                 test.InputClipBoxes,
 			}
 			detections, err := y.processOutputs(test.InputFrame, test.InputOutputs, test.InputFilter)
 			if test.ExpectError {
@@ -332,7 +721,43 @@ func (s *YoloTestSuite) TestProcessOutputs() {
 			} else {
 				s.Require().NoError(err)
 			}
-			s.Equal(test.Result, detections)
+			s.Equal(withBoxes(image.Pt(test.InputFrame.Cols(), test.InputFrame.Rows()), test.Result), detections)
+		})
+	}
+}
+
+func (s *YoloTestSuite) TestResolveClassConfidenceThresholds() {
+	tests := []struct {
+		Name       string
+		Thresholds map[string]float32
+		Result     map[int]float32
+		Error      string
+	}{
+		{
+			Name:       "By name and id",
+			Thresholds: map[string]float32{"coffee": 0.8, "0": 0.3},
+			Result:     map[int]float32{0: 0.3, 1: 0.8},
+		},
+		{
+			Name:       "Unknown name",
+			Thresholds: map[string]float32{"tea": 0.8},
+			Error:      `invalid class confidence threshold: unknown class "tea"`,
+		},
+		{
+			Name:       "Id out of range",
+			Thresholds: map[string]float32{"2": 0.8},
+			Error:      `invalid class confidence threshold: unknown class "2"`,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			thresholds, err := resolveClassConfidenceThresholds(test.Thresholds, labelsFromNames([]string{"laptop", "coffee"}))
+			if test.Error != "" {
+				s.EqualError(err, test.Error)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Result, thresholds)
 		})
 	}
 }
@@ -355,7 +780,7 @@ func (s *YoloTestSuite) TestGetDetections() {
 				neuralNetMock := mocks.NewMockNeuralNet(controller)
 				neuralNetMock.EXPECT().SetInput(gomock.Any(), "data").Times(1)
 
-				neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).Return(func() []gocv.Mat {
+				neuralNetMock.EXPECT().ForwardLayers([]string{"yolo_82", "yolo_94", "yolo_106"}).Return(func() []gocv.Mat {
 					laptopDetection := laptopDetection()
 					coffeeDetection := coffeeDetection()
 
@@ -394,7 +819,9 @@ func (s *YoloTestSuite) TestGetDetections() {
 	for _, test := range tests {
 		s.Run(test.Name, func() {
 			y := &yoloNet{
-				cocoNames:           []string{"laptop", "coffee"},
+				labels:              labelsFromNames([]string{"laptop", "coffee"}),
+				inputName:           "data",
+				outputLayers:        []string{"yolo_82", "yolo_94", "yolo_106"},
 				confidenceThreshold: test.InputConfidenceThreshHold,
 				net:                 test.SetupNeuralNetMock(),
 			}
@@ -407,12 +834,104 @@ func (s *YoloTestSuite) TestGetDetections() {
 				} else {
 					s.Require().NoError(err)
 				}
-				s.Equal(test.Result, detections)
+				s.Equal(withBoxes(image.Pt(test.InputFrame.Cols(), test.InputFrame.Rows()), test.Result), detections)
 			}
 		})
 	}
 }
 
+func (s *YoloTestSuite) TestGetDetectionsContext() {
+	tests := []struct {
+		Name               string
+		Context            func() (context.Context, context.CancelFunc)
+		SetupNeuralNetMock func(cancel context.CancelFunc) *mocks.MockNeuralNet
+		Result             []ObjectDetection
+		Error              error
+	}{
+		{
+			Name: "Get successful detection",
+			Context: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			SetupNeuralNetMock: func(context.CancelFunc) *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetInput(gomock.Any(), "data").Times(1)
+				neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).Return([]gocv.Mat{laptopDetection()}).Times(1)
+				return neuralNetMock
+			},
+			Result: []ObjectDetection{
+				{
+					ClassID:     0,
+					Confidence:  9,
+					ClassName:   "laptop",
+					BoundingBox: image.Rect(1, 1, 3, 3),
+				},
+			},
+		},
+		{
+			Name: "Cancelled before preprocessing",
+			Context: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			SetupNeuralNetMock: func(context.CancelFunc) *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				return mocks.NewMockNeuralNet(controller)
+			},
+			Error: context.Canceled,
+		},
+		{
+			Name: "Deadline exceeded before preprocessing",
+			Context: func() (context.Context, context.CancelFunc) {
+				return context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			},
+			SetupNeuralNetMock: func(context.CancelFunc) *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				return mocks.NewMockNeuralNet(controller)
+			},
+			Error: context.DeadlineExceeded,
+		},
+		{
+			Name: "Cancelled during forward",
+			Context: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			SetupNeuralNetMock: func(cancel context.CancelFunc) *mocks.MockNeuralNet {
+				controller := gomock.NewController(s.T())
+				neuralNetMock := mocks.NewMockNeuralNet(controller)
+				neuralNetMock.EXPECT().SetInput(gomock.Any(), "data").Times(1)
+				neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).DoAndReturn(func([]string) []gocv.Mat {
+					cancel()
+					return []gocv.Mat{laptopDetection()}
+				}).Times(1)
+				return neuralNetMock
+			},
+			Error: context.Canceled,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			ctx, cancel := test.Context()
+			defer cancel()
+			y := &yoloNet{
+				labels:       labelsFromNames([]string{"laptop", "coffee"}),
+				inputName:    "data",
+				outputLayers: []string{"yolo_82", "yolo_94", "yolo_106"},
+				net:          test.SetupNeuralNetMock(cancel),
+			}
+			detections, err := y.GetDetectionsContext(ctx, gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F))
+			if test.Error != nil {
+				s.ErrorIs(err, test.Error)
+			} else {
+				s.Require().NoError(err)
+			}
+			s.Equal(withBoxes(image.Pt(2, 2), test.Result), detections)
+		})
+	}
+}
+
 func laptopDetection() gocv.Mat {
 	laptopDetection := gocv.NewMatWithSize(1, 10, gocv.MatTypeCV32F)
 	laptopDetection.SetFloatAt(0, 0, 1)
@@ -424,6 +943,29 @@ func laptopDetection() gocv.Mat {
 	return laptopDetection
 }
 
+// withBoxes sets the sub-pixel boxes of the detections without one to their bounding boxes in a frame of given size.
+func withBoxes(frameSize image.Point, detections []ObjectDetection) []ObjectDetection {
+	if detections == nil {
+		return nil
+	}
+	result := []ObjectDetection{}
+	for _, detection := range detections {
+		if detection.Box == (Box{}) {
+			detection.setBox(BoxFromRect(detection.BoundingBox), frameSize)
+		}
+		result = append(result, detection)
+	}
+	return result
+}
+
+func multiLabelDetection() gocv.Mat {
+	detection := laptopDetection()
+	detection.SetFloatAt(0, 4, 0.8)
+	// Index for coffee == 6
+	detection.SetFloatAt(0, 6, 5)
+	return detection
+}
+
 func coffeeDetection() gocv.Mat {
 	coffeeDetection := gocv.NewMatWithSize(1, 10, gocv.MatTypeCV32F)
 	coffeeDetection.SetFloatAt(0, 1, 1)